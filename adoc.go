@@ -0,0 +1,63 @@
+package ftable
+
+import (
+	"io"
+	"strings"
+)
+
+// adocEscaper escapes '|' in AsciiDoc table cells and writes line breaks as hard line breaks.
+var adocEscaper = strings.NewReplacer("|", `\|`, lineBreak, " +\n")
+
+// adocAligns maps alignments to their AsciiDoc column specifiers.
+var adocAligns = map[Alignment]string{
+	AlignLeft:   "<",
+	AlignRight:  ">",
+	AlignCenter: "^",
+}
+
+// writeAsciiDoc writes rows to w as an AsciiDoc table, one row per line. If aligns is not nil, the table's cols
+// attribute sets the alignment of each column. If header or footer is set, the first or last row, respectively,
+// is marked as the table's header or footer by its options attribute.
+func writeAsciiDoc(w io.Writer, rows [][]string, aligns []Alignment, header, footer bool) {
+	ncols := len(columnWidths(rows))
+	if ncols == 0 {
+		return
+	}
+
+	var attrs []string
+	if aligns != nil {
+		specs := make([]string, ncols)
+		for i := range specs {
+			specs[i] = adocAligns[aligns[i]]
+		}
+		attrs = append(attrs, `cols="`+strings.Join(specs, ",")+`"`)
+	}
+	var options []string
+	if header {
+		options = append(options, "header")
+	}
+	if footer && len(rows) > 1 {
+		options = append(options, "footer")
+	}
+	if options != nil {
+		attrs = append(attrs, `options="`+strings.Join(options, ",")+`"`)
+	}
+	if attrs != nil {
+		io.WriteString(w, "["+strings.Join(attrs, ", ")+"]\n")
+	}
+
+	io.WriteString(w, "|===\n")
+	for _, row := range rows {
+		var b strings.Builder
+		for i := 0; i < ncols; i++ {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteByte('|')
+			b.WriteString(adocEscaper.Replace(cell(row, i)))
+		}
+		b.WriteByte('\n')
+		io.WriteString(w, b.String())
+	}
+	io.WriteString(w, "|===\n")
+}
@@ -0,0 +1,73 @@
+package ftable
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAsciiDocGolden(t *testing.T) {
+	const in = "name\tqty\tnote\napple\t3\tred|green\nkiwi\t12\t\nwatermelon\t1\tbig\vheavy\ntotal\t16\t\n"
+	tests := []struct {
+		golden string
+		opts   Options
+	}{
+		{"adoc.golden", Options{Format: "adoc"}},
+		{"adoc-header-footer.golden", Options{Format: "adoc", Header: true, Footer: true}},
+		{"adoc-aligned.golden", Options{Format: "adoc", Header: true, AutoNum: true, Aligns: []Alignment{AlignCenter}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			got, err := RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.golden, got)
+			checkAsciiDocTable(t, got, 3)
+		})
+	}
+}
+
+// adocCellStart matches the '|' beginning an AsciiDoc table cell: one that is not escaped.
+var adocCellStart = regexp.MustCompile(`(^|[^\\])\|`)
+
+// checkAsciiDocTable fails t unless out is an AsciiDoc table of ncols columns, as Asciidoctor parses one: an
+// optional attribute list, whose cols attribute, if any, has ncols specifiers, then the table delimited by
+// "|===" lines, holding a whole number of rows of cells, each begun by an unescaped '|'. Lines continuing a cell
+// after a hard line break (" +") hold no cells of their own, and each row, as written by writeAsciiDoc, ends a
+// line.
+func checkAsciiDocTable(t *testing.T, out string, ncols int) {
+	t.Helper()
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if strings.HasPrefix(lines[0], "[") {
+		if !strings.HasSuffix(lines[0], "]") {
+			t.Fatalf("unterminated attribute list %q", lines[0])
+		}
+		if m := regexp.MustCompile(`cols="([^"]*)"`).FindStringSubmatch(lines[0]); m != nil {
+			if n := len(strings.Split(m[1], ",")); n != ncols {
+				t.Errorf("cols attribute has %d specifiers, want %d", n, ncols)
+			}
+		}
+		lines = lines[1:]
+	}
+	if len(lines) < 2 || lines[0] != "|===" || lines[len(lines)-1] != "|===" {
+		t.Fatalf("table is not delimited by |===:\n%s", out)
+	}
+
+	cells := 0
+	continued := false
+	for _, line := range lines[1 : len(lines)-1] {
+		n := len(adocCellStart.FindAllString(line, -1))
+		switch {
+		case continued && n > 0:
+			t.Errorf("line %q continuing a cell begins cells of its own", line)
+		case !continued && !strings.HasPrefix(line, "|"):
+			t.Errorf("line %q does not begin a cell", line)
+		}
+		cells += n
+		if continued = strings.HasSuffix(line, " +"); !continued && cells%ncols != 0 {
+			t.Errorf("row ending with line %q leaves %d cells over %d columns", line, cells, ncols)
+			return
+		}
+	}
+}
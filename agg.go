@@ -0,0 +1,186 @@
+package ftable
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Aggregate is a function computed over the data cells of a column, such as its sum.
+type Aggregate struct {
+	// Col is the 0-based index of the column to aggregate.
+	Col int
+	// Func names the function to compute: "sum", "avg", "min", or "max" of the column's numeric cells, or
+	// "count" of its non-empty cells.
+	Func string
+}
+
+// ParseAggregates parses a comma-separated list of aggregates, each a 1-based column number and the name of a
+// function, separated by a colon, such as "2:sum,3:avg". A column may have only one aggregate.
+func ParseAggregates(v string) ([]Aggregate, error) {
+	var aggs []Aggregate
+	for _, field := range strings.Split(v, ",") {
+		col, fn, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid aggregate %q: must be of the form column:function", field)
+		}
+
+		n, err := strconv.Atoi(col)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid column %q", col)
+		}
+
+		switch fn {
+		case "sum", "avg", "min", "max", "count":
+		default:
+			return nil, fmt.Errorf("unrecognized aggregate function %q", fn)
+		}
+		for _, a := range aggs {
+			if a.Col == n-1 {
+				return nil, fmt.Errorf("column %d has more than one aggregate", n)
+			}
+		}
+		aggs = append(aggs, Aggregate{Col: n - 1, Func: fn})
+	}
+	return aggs, nil
+}
+
+// validateAggregates returns an error if any of aggs has an unrecognized function or shares its column with
+// another, since each column's aggregate fills its cell of the footer.
+func validateAggregates(aggs []Aggregate) error {
+	for i, a := range aggs {
+		switch a.Func {
+		case "sum", "avg", "min", "max", "count":
+		default:
+			return fmt.Errorf("ftable: unrecognized aggregate function %q", a.Func)
+		}
+		for _, b := range aggs[:i] {
+			if b.Col == a.Col {
+				return fmt.Errorf("ftable: column %d has more than one aggregate", a.Col+1)
+			}
+		}
+	}
+	return nil
+}
+
+// aggregateRow returns a row holding the aggregates aggs computed over the cells of rows. A column with no
+// aggregate is empty.
+func aggregateRow(rows [][]string, aggs []Aggregate) []string {
+	var agg []string
+	for _, a := range aggs {
+		for len(agg) <= a.Col {
+			agg = append(agg, "")
+		}
+		agg[a.Col] = aggregate(rows, a)
+	}
+	return agg
+}
+
+// aggregate returns the result of a computed over the cells of rows. Numeric results are written with as many
+// decimal places as the most precise of the cells, or at least two for averages. Cells that are not numbers are
+// skipped by all functions but count, and the sum, min, or max of no numbers is empty.
+func aggregate(rows [][]string, a Aggregate) string {
+	var sum float64
+	min, max := math.Inf(1), math.Inf(-1)
+	count, numbers, prec := 0, 0, 0
+	for _, row := range rows {
+		c := cell(row, a.Col)
+		if c == "" {
+			continue
+		}
+		count++
+
+		v, err := strconv.ParseFloat(c, 64)
+		if err != nil {
+			continue
+		}
+		numbers++
+		sum += v
+		min, max = math.Min(min, v), math.Max(max, v)
+		if i := strings.IndexByte(c, '.'); i >= 0 && len(c)-i-1 > prec {
+			prec = len(c) - i - 1
+		}
+	}
+
+	if a.Func == "count" {
+		return strconv.Itoa(count)
+	} else if numbers == 0 {
+		return ""
+	}
+
+	switch a.Func {
+	case "avg":
+		if prec < 2 {
+			prec = 2
+		}
+		return strconv.FormatFloat(sum/float64(numbers), 'f', prec, 64)
+	case "min":
+		return strconv.FormatFloat(min, 'f', prec, 64)
+	case "max":
+		return strconv.FormatFloat(max, 'f', prec, 64)
+	}
+	return strconv.FormatFloat(sum, 'f', prec, 64)
+}
+
+// A groupRole is the part a data row plays in the groups formed by GroupBy.
+type groupRole uint8
+
+const (
+	groupLeader   groupRole = iota // The first row of a group, which keeps its key.
+	groupMember                    // A later row of a group, whose key is blanked.
+	groupSubtotal                  // A row of aggregates computed over the group before it.
+)
+
+// groupRows returns rows, which are sorted by their cells in column col, with the cell in col blanked in every row
+// after the first of each group of rows sharing a cell in col. If aggs is not nil, a subtotal row of aggs computed
+// over each group follows it. The groupRole of each returned row is returned with it.
+func groupRows(rows [][]string, col int, aggs []Aggregate) ([][]string, []groupRole) {
+	grouped := make([][]string, 0, len(rows))
+	roles := make([]groupRole, 0, len(rows))
+	for len(rows) > 0 {
+		key := cell(rows[0], col)
+		n := 1
+		for n < len(rows) && cell(rows[n], col) == key {
+			n++
+		}
+
+		group := rows[:n]
+		var subtotal []string
+		if aggs != nil {
+			subtotal = aggregateRow(group, aggs)
+		}
+		for _, row := range group[1:] {
+			if col < len(row) {
+				row[col] = ""
+			}
+		}
+
+		grouped = append(grouped, group...)
+		roles = append(roles, groupLeader)
+		for range group[1:] {
+			roles = append(roles, groupMember)
+		}
+		if subtotal != nil {
+			grouped = append(grouped, subtotal)
+			roles = append(roles, groupSubtotal)
+		}
+		rows = rows[n:]
+	}
+	return grouped, roles
+}
+
+// withoutSubtotals returns the rows of data whose roles, if not nil, are not groupSubtotal. The returned rows
+// are those of data, not copies.
+func withoutSubtotals(data [][]string, roles []groupRole) [][]string {
+	if roles == nil {
+		return data
+	}
+	rows := make([][]string, 0, len(data))
+	for n, row := range data {
+		if roles[n] != groupSubtotal {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,249 @@
+package ftable
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseAggregates(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []Aggregate
+		wantErr bool
+	}{
+		{in: "2:sum", want: []Aggregate{{Col: 1, Func: "sum"}}},
+		{in: "1:count,3:avg,4:min,5:max", want: []Aggregate{{0, "count"}, {2, "avg"}, {3, "min"}, {4, "max"}}},
+		{in: "2", wantErr: true},
+		{in: "0:sum", wantErr: true},
+		{in: "x:sum", wantErr: true},
+		{in: "2:median", wantErr: true},
+		{in: "2:sum,2:avg", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAggregates(tt.in)
+		switch {
+		case tt.wantErr && err == nil:
+			t.Errorf("ParseAggregates(%q) = %v, want an error", tt.in, got)
+		case !tt.wantErr && err != nil:
+			t.Errorf("ParseAggregates(%q) = %v", tt.in, err)
+		case !reflect.DeepEqual(got, tt.want):
+			t.Errorf("ParseAggregates(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	rows := [][]string{{"3"}, {"1.5"}, {""}, {"n/a"}, {"-2.5"}, {"10"}}
+	empty := [][]string{{""}, {"n/a"}, {}}
+	tests := []struct {
+		fn         string
+		want       string
+		wantEmpty  string
+		wantNoRows string
+	}{
+		{"sum", "12.0", "", ""},
+		{"avg", "3.00", "", ""},
+		{"min", "-2.5", "", ""},
+		{"max", "10.0", "", ""},
+		{"count", "5", "1", "0"},
+	}
+	for _, tt := range tests {
+		a := Aggregate{Col: 0, Func: tt.fn}
+		if got := aggregate(rows, a); got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.fn, got, tt.want)
+		}
+		if got := aggregate(empty, a); got != tt.wantEmpty {
+			t.Errorf("%s of no numbers = %q, want %q", tt.fn, got, tt.wantEmpty)
+		}
+		if got := aggregate(nil, a); got != tt.wantNoRows {
+			t.Errorf("%s of no rows = %q, want %q", tt.fn, got, tt.wantNoRows)
+		}
+	}
+}
+
+func TestAggregateRow(t *testing.T) {
+	rows := [][]string{{"a", "1", "x"}, {"b", "2", ""}, {"c", "4"}}
+	got := aggregateRow(rows, []Aggregate{{Col: 1, Func: "sum"}, {Col: 2, Func: "count"}})
+	if want := []string{"", "7", "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("aggregateRow() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderAggregates(t *testing.T) {
+	const in = "item\tqty\tprice\napple\t3\t1.25\npear\t\t0.5\nfig\t12\tn/a\n"
+	tests := []struct {
+		name    string
+		aggs    []Aggregate
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "sum and avg",
+			aggs: []Aggregate{{Col: 1, Func: "sum"}, {Col: 2, Func: "avg"}},
+			want: "item\tqty\tprice\napple\t3\t1.25\npear\t\t0.5\nfig\t12\tn/a\n\t15\t0.88\n",
+		},
+		{
+			name: "count",
+			aggs: []Aggregate{{Col: 0, Func: "count"}, {Col: 1, Func: "count"}},
+			want: "item\tqty\tprice\napple\t3\t1.25\npear\t\t0.5\nfig\t12\tn/a\n3\t2\n",
+		},
+		{name: "duplicate column", aggs: []Aggregate{{Col: 1, Func: "sum"}, {Col: 1, Func: "max"}}, wantErr: true},
+		{name: "unknown function", aggs: []Aggregate{{Col: 1, Func: "median"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(in, Options{Header: true, Aggregates: tt.aggs, Format: "tsv"})
+			switch {
+			case tt.wantErr && err == nil:
+				t.Errorf("got:\n%s\nwant an error", got)
+			case !tt.wantErr && err != nil:
+				t.Error(err)
+			case got != tt.want:
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupRows(t *testing.T) {
+	tests := []struct {
+		name string
+		aggs []Aggregate
+		want [][]string
+		// roles spells the groupRole of each row: L for a leader, M for a member, and S for a subtotal.
+		roles string
+	}{
+		{
+			name:  "without subtotals",
+			want:  [][]string{{"east", "1"}, {"", "2"}, {"west", "5"}, {"", "7"}, {"", "1"}},
+			roles: "LMLMM",
+		},
+		{
+			name:  "sum subtotals",
+			aggs:  []Aggregate{{Col: 1, Func: "sum"}},
+			want:  [][]string{{"east", "1"}, {"", "2"}, {"", "3"}, {"west", "5"}, {"", "7"}, {"", "1"}, {"", "13"}},
+			roles: "LMSLMMS",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := [][]string{{"east", "1"}, {"east", "2"}, {"west", "5"}, {"west", "7"}, {"west", "1"}}
+			got, roles := groupRows(rows, 0, tt.aggs)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupRows() = %q, want %q", got, tt.want)
+			}
+			var spelled []byte
+			for _, role := range roles {
+				spelled = append(spelled, "LMS"[role])
+			}
+			if string(spelled) != tt.roles {
+				t.Errorf("groupRows() roles = %s, want %s", spelled, tt.roles)
+			}
+		})
+	}
+}
+
+func TestRenderGroupBy(t *testing.T) {
+	const in = "region\tsales\tpaid\nwest\t5\tyes\neast\t1\tno\nwest\t7\t\neast\t2\tyes\n"
+	aggs := []Aggregate{{Col: 1, Func: "sum"}}
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			"grouped", Options{Header: true, GroupBy: 1, Aggregates: aggs, Box: true},
+			"" +
+				"┏━━━━━━━━┳━━━━━━━┳━━━━━━┓\n" +
+				"┃ region ┃ sales ┃ paid ┃\n" +
+				"┡━━━━━━━━╇━━━━━━━╇━━━━━━┩\n" +
+				"│ east   │ 1     │ no   │\n" +
+				"│        │ 2     │ yes  │\n" +
+				"│        │ 3     │      │\n" +
+				"│ west   │ 5     │ yes  │\n" +
+				"│        │ 7     │      │\n" +
+				"│        │ 12    │      │\n" +
+				"┢━━━━━━━━╈━━━━━━━╈━━━━━━┪\n" +
+				"┃        ┃ 15    ┃      ┃\n" +
+				"┗━━━━━━━━┻━━━━━━━┻━━━━━━┛\n",
+		},
+		{
+			"numbered", Options{Header: true, GroupBy: 1, Aggregates: aggs, Box: true, Number: true, NumberFrom: 1, Empty: "-"},
+			"" +
+				"┏━━━┳━━━━━━━━┳━━━━━━━┳━━━━━━┓\n" +
+				"┃   ┃ region ┃ sales ┃ paid ┃\n" +
+				"┡━━━╇━━━━━━━━╇━━━━━━━╇━━━━━━┩\n" +
+				"│ 1 │ east   │ 1     │ no   │\n" +
+				"│ 2 │        │ 2     │ yes  │\n" +
+				"│   │        │ 3     │      │\n" +
+				"│ 3 │ west   │ 5     │ yes  │\n" +
+				"│ 4 │        │ 7     │ -    │\n" +
+				"│   │        │ 12    │      │\n" +
+				"┢━━━╈━━━━━━━━╈━━━━━━━╈━━━━━━┪\n" +
+				"┃   ┃        ┃ 15    ┃      ┃\n" +
+				"┗━━━┻━━━━━━━━┻━━━━━━━┻━━━━━━┛\n",
+		},
+		{
+			"humanized and checked", Options{Header: true, GroupBy: 1, Aggregates: aggs, Box: true, Humanize: []int{1}, Checks: []int{2}},
+			"" +
+				"┏━━━━━━━━┳━━━━━━━┳━━━━━━┓\n" +
+				"┃ region ┃ sales ┃ paid ┃\n" +
+				"┡━━━━━━━━╇━━━━━━━╇━━━━━━┩\n" +
+				"│ east   │ 1 B   │  ✗   │\n" +
+				"│        │ 2 B   │  ✓   │\n" +
+				"│        │ 3 B   │      │\n" +
+				"│ west   │ 5 B   │  ✓   │\n" +
+				"│        │ 7 B   │      │\n" +
+				"│        │ 12 B  │      │\n" +
+				"┢━━━━━━━━╈━━━━━━━╈━━━━━━┪\n" +
+				"┃        ┃ 15 B  ┃      ┃\n" +
+				"┗━━━━━━━━┻━━━━━━━┻━━━━━━┛\n",
+		},
+		{
+			"tsv", Options{Header: true, GroupBy: 1, Aggregates: aggs, Format: "tsv"},
+			"region\tsales\tpaid\neast\t1\tno\neast\t2\tyes\nwest\t5\tyes\nwest\t7\t\n\t15\n",
+		},
+		{
+			"csv", Options{Header: true, GroupBy: 1, Format: "csv"},
+			"region,sales,paid\neast,1,no\neast,2,yes\nwest,5,yes\nwest,7,\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupByZebra(t *testing.T) {
+	const in = "region\tsales\nwest\t5\neast\t1\nwest\t7\neast\t2\n"
+	opts := Options{Box: true, Header: true, GroupBy: 1, Aggregates: []Aggregate{{Col: 1, Func: "sum"}}, Zebra: [2]string{"7", "2"}}
+	got, err := RenderString(in, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Stripes alternate over the data rows alone, skipping the subtotal rows after each group.
+	var colors []string
+	for _, line := range strings.Split(got, "\n") {
+		if !strings.HasPrefix(stripANSI(line), "│") {
+			continue
+		}
+		color := ""
+		if m := regexp.MustCompile("\x1b\\[([0-9;]+)m").FindStringSubmatch(line); m != nil {
+			color = m[1]
+		}
+		colors = append(colors, color)
+	}
+	if want := []string{"7", "2", "", "7", "2", ""}; !reflect.DeepEqual(colors, want) {
+		t.Errorf("row colors = %q, want %q:\n%s", colors, want, got)
+	}
+}
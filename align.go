@@ -0,0 +1,140 @@
+package ftable
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Alignment is the horizontal alignment of the cells in a column.
+type Alignment byte
+
+// Column alignments, as parsed by ParseAlignments.
+const (
+	AlignLeft   Alignment = 'l'
+	AlignRight  Alignment = 'r'
+	AlignCenter Alignment = 'c'
+)
+
+// ParseAlignments parses a comma-separated list of column alignments, each one of "l", "r", or "c". An empty
+// entry is left-aligned.
+func ParseAlignments(v string) ([]Alignment, error) {
+	names := strings.Split(v, ",")
+	aligns := make([]Alignment, len(names))
+	for i, name := range names {
+		switch name {
+		case "", "l":
+			aligns[i] = AlignLeft
+		case "r":
+			aligns[i] = AlignRight
+		case "c":
+			aligns[i] = AlignCenter
+		default:
+			return nil, fmt.Errorf("unrecognized alignment %q for column %d", name, i+1)
+		}
+	}
+	return aligns, nil
+}
+
+// alignRows pads each cell of rows, in place, to at least minwidth or the width of its column, whichever
+// is greater, using the column's alignment from aligns. Columns beyond the end of aligns are left-aligned. The
+// last cell of a row is not padded if it is left-aligned, so that lines do not end in padding. The cells of
+// column indentCol, if not negative, are aligned after their leading whitespace, as by alignIndented.
+func alignRows(rows [][]string, aligns []Alignment, minwidth int, pad rune, indentCol int) {
+	widths := columnWidths(rows)
+	for i, n := range widths {
+		if n < minwidth {
+			widths[i] = minwidth
+		}
+	}
+
+	for _, row := range rows {
+		for i, cell := range row {
+			align := AlignLeft
+			if i < len(aligns) {
+				align = aligns[i]
+			}
+			switch {
+			case align == AlignLeft && i == len(row)-1:
+			case i == indentCol:
+				row[i] = alignIndented(cell, widths[i], align, pad)
+			default:
+				row[i] = alignCell(cell, widths[i], align, pad)
+			}
+		}
+	}
+}
+
+// alignCell pads s with pad to a display width of n according to align. Centered cells place any odd extra space
+// to the right.
+func alignCell(s string, n int, align Alignment, pad rune) string {
+	return padCell(s, displayWidth(s), n, align, pad)
+}
+
+// alignIndented is alignCell for a cell whose leading whitespace, its indent, is kept at its start: the rest of
+// s is aligned within the width that remains.
+func alignIndented(s string, n int, align Alignment, pad rune) string {
+	indent, rest := splitIndent(s)
+	width := displayWidth(indent)
+	return indent + alignCell(rest, n-width, align, pad)
+}
+
+// splitIndent splits s after its leading whitespace.
+func splitIndent(s string) (indent, rest string) {
+	rest = strings.TrimLeftFunc(s, unicode.IsSpace)
+	return s[:len(s)-len(rest)], rest
+}
+
+// padCell is alignCell for a string s already known to be width display columns wide.
+func padCell(s string, width, n int, align Alignment, pad rune) string {
+	space := n - width
+	if space <= 0 {
+		return s
+	}
+
+	p := string(pad)
+	switch align {
+	case AlignRight:
+		return strings.Repeat(p, space) + s
+	case AlignCenter:
+		left := space / 2
+		return strings.Repeat(p, left) + s + strings.Repeat(p, space-left)
+	default:
+		return s + strings.Repeat(p, space)
+	}
+}
+
+// numericColumns reports, for each column of rows, whether every non-empty cell in it parses as a number or a
+// size in bytes, such as "1.5 KiB", ignoring any digit grouping characters group (if not zero) and colors. Cells holding
+// the placeholder empty are treated as empty. A column with no non-empty cells is not numeric.
+func numericColumns(rows [][]string, group rune, empty string) []bool {
+	numeric := make([]bool, len(columnWidths(rows)))
+	seen := make([]bool, len(numeric))
+	for i := range numeric {
+		numeric[i] = true
+	}
+
+	for _, row := range rows {
+		for i, cell := range row {
+			if cell == "" || cell == empty || !numeric[i] {
+				continue
+			}
+			if strings.IndexByte(cell, '\x1b') >= 0 {
+				cell = stripANSI(cell)
+			}
+			if group != 0 {
+				cell = strings.Replace(cell, string(group), "", -1)
+			}
+			if _, err := strconv.ParseFloat(cell, 64); err != nil {
+				numeric[i] = isByteSize(cell)
+			}
+			seen[i] = true
+		}
+	}
+
+	for i := range numeric {
+		numeric[i] = numeric[i] && seen[i]
+	}
+	return numeric
+}
@@ -0,0 +1,129 @@
+package ftable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAlignIndented(t *testing.T) {
+	tests := []struct {
+		s     string
+		n     int
+		align Alignment
+		want  string
+	}{
+		{"  ab", 6, AlignLeft, "  ab  "},
+		{"  ab", 6, AlignRight, "    ab"},
+		{"  ab", 7, AlignCenter, "   ab  "},
+		{"ab", 4, AlignRight, "  ab"},
+		{"    ab", 4, AlignRight, "    ab"},
+		{"\t ab", 5, AlignRight, "\t   ab"},
+	}
+	for _, tt := range tests {
+		if got := alignIndented(tt.s, tt.n, tt.align, ' '); got != tt.want {
+			t.Errorf("alignIndented(%q, %d, %c) = %q, want %q", tt.s, tt.n, tt.align, got, tt.want)
+		}
+	}
+}
+
+func TestRenderKeepIndent(t *testing.T) {
+	const in = "src  \t4\n  cmd\t12\n    ftable \t1\n  testdata\t7\n"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			"trimmed", Options{TrimLeft: true, TrimRight: true, KeepIndent: true, Padding: 1},
+			"src        4\n" +
+				"  cmd      12\n" +
+				"    ftable 1\n" +
+				"  testdata 7\n",
+		},
+		{
+			"right-aligned", Options{TrimLeft: true, TrimRight: true, KeepIndent: true, Padding: 1, Aligns: []Alignment{AlignRight, AlignRight}},
+			"       src  4\n" +
+				"       cmd 12\n" +
+				"    ftable  1\n" +
+				"  testdata  7\n",
+		},
+		{
+			"box", Options{Box: true, TrimLeft: true, TrimRight: true, KeepIndent: true, Aligns: []Alignment{AlignRight, AlignRight}},
+			"┌────────────┬────┐\n" +
+				"│        src │  4 │\n" +
+				"│        cmd │ 12 │\n" +
+				"│     ftable │  1 │\n" +
+				"│   testdata │  7 │\n" +
+				"└────────────┴────┘\n",
+		},
+		{
+			"numbered", Options{Box: true, TrimLeft: true, TrimRight: true, KeepIndent: true, Number: true, NumberFrom: 1},
+			"┌───┬────────────┬────┐\n" +
+				"│ 1 │ src        │ 4  │\n" +
+				"│ 2 │   cmd      │ 12 │\n" +
+				"│ 3 │     ftable │ 1  │\n" +
+				"│ 4 │   testdata │ 7  │\n" +
+				"└───┴────────────┴────┘\n",
+		},
+		{
+			"without", Options{TrimLeft: true, TrimRight: true, Padding: 1},
+			"src      4\ncmd      12\nftable   1\ntestdata 7\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if tt.opts.Box {
+				checkAligned(t, got)
+			}
+		})
+	}
+}
+
+func TestBoxKeepIndentWrapped(t *testing.T) {
+	got, err := RenderString("root\n    a long nested entry\n", Options{Box: true, KeepIndent: true, Width: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAligned(t, got)
+	lines := strings.Split(got, "\n")
+	for _, line := range lines[2 : len(lines)-2] {
+		if !strings.HasPrefix(line, "│     ") {
+			t.Errorf("wrapped line %q is not indented", line)
+		}
+	}
+}
+
+func TestNumericColumns(t *testing.T) {
+	tests := []struct {
+		name string
+		col  []string
+		want bool
+	}{
+		{"numbers", []string{"1", "-2.5", "3e2"}, true},
+		{"sizes in bytes", []string{"512 B", "1.5 KiB", "2 kB", "3GB"}, true},
+		{"exponent without digits", []string{"1", "2e"}, false},
+		{"minutes", []string{"5m", "10m"}, false},
+		{"bare unit prefix", []string{"3T"}, false},
+		{"lowercase bytes", []string{"4b"}, false},
+		{"words", []string{"1", "apple"}, false},
+		{"empty", []string{"", ""}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows [][]string
+			for _, cell := range tt.col {
+				rows = append(rows, []string{cell})
+			}
+			if got := numericColumns(rows, 0, ""); got[0] != tt.want {
+				t.Errorf("numericColumns(%q) = %v, want %v", tt.col, got[0], tt.want)
+			}
+		})
+	}
+}
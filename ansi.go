@@ -0,0 +1,149 @@
+package ftable
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ansiEscape matches ANSI CSI escape sequences, such as the SGR sequences used to color text, and OSC escape
+// sequences, such as the OSC 8 sequences that delimit hyperlinks.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(?:\x07|\x1b\\\\)")
+
+// sgrParams matches the parameters of an SGR escape sequence, such as "1;34".
+var sgrParams = regexp.MustCompile(`^[0-9]+(;[0-9]+)*$`)
+
+// colorNames maps the names accepted by ParseColor to their SGR parameters.
+var colorNames = map[string]string{
+	"bold":      "1",
+	"dim":       "2",
+	"italic":    "3",
+	"underline": "4",
+	"reverse":   "7",
+	"black":     "30",
+	"red":       "31",
+	"green":     "32",
+	"yellow":    "33",
+	"blue":      "34",
+	"magenta":   "35",
+	"cyan":      "36",
+	"white":     "37",
+}
+
+// ParseColor parses a color, either one of the names black, red, green, yellow, blue, magenta, cyan, white, bold,
+// dim, italic, underline, or reverse, or the semicolon-separated parameters of an SGR escape sequence (such as
+// "1;34" for bold blue), and returns its SGR parameters.
+func ParseColor(v string) (string, error) {
+	if sgr, ok := colorNames[v]; ok {
+		return sgr, nil
+	}
+	if !sgrParams.MatchString(v) {
+		return "", fmt.Errorf("unrecognized color %q", v)
+	}
+	return v, nil
+}
+
+// colorize returns s wrapped in the SGR escape sequence with the parameters sgr, followed by a reset. Resets
+// within s, such as those ending colored cells of a colored row, are followed by sgr again. If sgr or s is empty,
+// it returns s unchanged.
+func colorize(s, sgr string) string {
+	if sgr == "" || s == "" {
+		return s
+	}
+	set := "\x1b[" + sgr + "m"
+	if strings.Contains(s, sgrReset) {
+		s = strings.Replace(s, sgrReset, sgrReset+set, -1)
+	}
+	return set + s + sgrReset
+}
+
+// sgrReset is the SGR escape sequence resetting all colors.
+const sgrReset = "\x1b[0m"
+
+// hyperlink returns each line of s wrapped in the OSC 8 escape sequences making it a terminal hyperlink to url.
+// Lines are linked separately, since they may be drawn apart. If url or a line is empty, or url contains a
+// control character that would end the sequence early, that line is returned unchanged.
+func hyperlink(s, url string) string {
+	if url == "" || strings.IndexFunc(url, unicode.IsControl) >= 0 {
+		return s
+	}
+
+	lines := cellLines(s)
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "\x1b]8;;" + url + "\x1b\\" + line + "\x1b]8;;\x1b\\"
+		}
+	}
+	return strings.Join(lines, lineBreak)
+}
+
+// escapeLen returns the length of the escape sequence at the start of s, or 0 if s does not begin with one.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' || (s[1] != '[' && s[1] != ']') {
+		return 0
+	}
+	if loc := ansiEscape.FindStringIndex(s); loc != nil && loc[0] == 0 {
+		return loc[1]
+	}
+	return 0
+}
+
+// stripANSI returns s without any ANSI escape sequences.
+func stripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+// reflowEscapes carries the colors and hyperlinks left open at the end of each of lines, the lines of a single
+// text, over to the next, so that each line can be drawn apart from the others: a line ends by resetting any
+// colors and closing any hyperlink it leaves open, and the next begins by reopening them. It modifies and returns
+// lines.
+func reflowEscapes(lines []string) []string {
+	var sgr []string // The SGR sequences in effect since the last reset.
+	var link string  // The OSC 8 sequence opening the current hyperlink, if any.
+	for i, line := range lines {
+		prefix := strings.Join(sgr, "") + link
+		for _, esc := range ansiEscape.FindAllString(line, -1) {
+			switch {
+			case esc == "\x1b[0m" || esc == "\x1b[m":
+				sgr = sgr[:0]
+			case strings.HasSuffix(esc, "m") && esc[1] == '[':
+				sgr = append(sgr, esc)
+			case strings.HasPrefix(esc, "\x1b]8;"):
+				link = ""
+				if params := strings.TrimRight(esc[len("\x1b]8;"):], "\x07\x1b\\"); !strings.HasSuffix(params, ";") {
+					link = esc
+				}
+			}
+		}
+
+		suffix := ""
+		if link != "" {
+			suffix += "\x1b]8;;\x1b\\"
+		}
+		if len(sgr) > 0 {
+			suffix += "\x1b[0m"
+		}
+		lines[i] = prefix + line + suffix
+	}
+	return lines
+}
+
+// escapes returns the ANSI escape sequences in s, without the text between them.
+func escapes(s string) string {
+	return strings.Join(ansiEscape.FindAllString(s, -1), "")
+}
+
+// ansiStripper is an io.Writer that removes ANSI escape sequences from everything written through it. Escape
+// sequences must not be split across writes.
+type ansiStripper struct {
+	w io.Writer
+}
+
+func (s ansiStripper) Write(p []byte) (int, error) {
+	if _, err := s.w.Write(ansiEscape.ReplaceAll(p, nil)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,64 @@
+package ftable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHyperlink(t *testing.T) {
+	const open, end = "\x1b]8;;https://example.com\x1b\\", "\x1b]8;;\x1b\\"
+	tests := []struct {
+		s, url, want string
+	}{
+		{"site", "https://example.com", open + "site" + end},
+		{"two\vlines", "https://example.com", open + "two" + end + "\v" + open + "lines" + end},
+		{"", "https://example.com", ""},
+		{"site", "", "site"},
+		{"site", "https://example.com/\x1b]", "site"},
+	}
+	for _, tt := range tests {
+		if got := hyperlink(tt.s, tt.url); got != tt.want {
+			t.Errorf("hyperlink(%q, %q) = %q, want %q", tt.s, tt.url, got, tt.want)
+		}
+		if got := stripANSI(hyperlink(tt.s, tt.url)); got != tt.s {
+			t.Errorf("hyperlink(%q, %q) without escapes = %q", tt.s, tt.url, got)
+		}
+	}
+}
+
+func TestRenderLinks(t *testing.T) {
+	const in = "name\turl\nexample\thttps://example.com\nlong name here\thttps://example.org/a/much/longer/path\n"
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"text", Options{Header: true, Padding: 1, Link: 2}},
+		{"box", Options{Box: true, Header: true, Link: 2}},
+		{"box linking names", Options{Box: true, Header: true, Link: 1, LinkURL: 2}},
+		{"wrapped box", Options{Box: true, Header: true, Link: 2, Width: 40}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			plain := tt.opts
+			plain.Link, plain.LinkURL = 0, 0
+			want, err := RenderString(in, plain)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if n := strings.Count(got, "\x1b]8;;https://"); n < 2 {
+				t.Errorf("got %d links, want at least 2:\n%q", n, got)
+			}
+			if stripANSI(got) != want {
+				t.Errorf("linked output, without its escapes:\n%s\nwant:\n%s", stripANSI(got), want)
+			}
+			if tt.opts.Box {
+				checkAligned(t, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,434 @@
+package ftable
+
+import (
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// boxColumn is the layout of a single column of a box.
+type boxColumn struct {
+	// width is the display width of the column's content, excluding the space around it.
+	width int
+	// minWidth is the narrowest the column may be fit: the width of its widest cluster, which cannot be
+	// wrapped any narrower, or its minimum ColWidth, if greater.
+	minWidth int
+	align    Alignment
+	// lead and trail are the space on either side of the column's content.
+	lead, trail string
+	// indent is set if the column's cells keep their leading whitespace at their start, aligning and wrapping
+	// only what follows it.
+	indent bool
+}
+
+// span returns the display width of c, including the space around its content.
+func (c *boxColumn) span() int {
+	return displayWidth(c.lead) + c.width + displayWidth(c.trail)
+}
+
+// box lays out rows of cells in a table drawn with box-drawing characters.
+//
+// A row with fewer cells than the widest row is drawn with empty cells in its remaining columns, so that every
+// row has a divider between each column.
+type box struct {
+	cols   []boxColumn
+	pad    rune
+	valign string
+	// outer is set if the box has an outer frame.
+	outer bool
+
+	// borders caches the border lines drawn by border, since most are drawn many times over (e.g., between
+	// every row, with RowLines). The columns of a box must not change once a border is drawn.
+	borders map[boxBorder]string
+}
+
+// newBox returns a box laying out rows with the tabwriter-style settings of opts: cells are separated from their
+// dividers by a space on the left and opts.Padding padding characters on the right (or, for right-aligned cells,
+// on the left, save for a space on the right), and no column is narrower than opts.MinWidth, including its padding.
+// A Padding of less than one is taken as one, so that cells are spaced evenly from the dividers on either side.
+// With opts.Compact, each cell has one space or padding character fewer on either side.
+func newBox(rows [][]string, opts *Options) *box {
+	ncols := len(columnWidths(rows))
+	b := &box{cols: make([]boxColumn, ncols), pad: opts.PadChar, valign: opts.VAlign, outer: !opts.NoOuter}
+
+	n := opts.Padding
+	if n < 1 {
+		n = 1
+	}
+	aligns := opts.columnAlignments(rows)
+	padding := strings.Repeat(string(opts.PadChar), n)
+	for i := range b.cols {
+		col := &b.cols[i]
+		col.width = opts.MinWidth - n
+		if col.width < 0 {
+			col.width = 0
+		}
+
+		col.align = aligns[i]
+		col.indent = i == opts.indentCol()
+
+		col.lead, col.trail = " ", padding
+		switch {
+		case i == ncols-1:
+			col.trail = " "
+		case col.align == AlignRight:
+			// Keep a space between a right-aligned cell and the divider that follows it.
+			col.lead, col.trail = " "+padding[len(string(opts.PadChar)):], " "
+		}
+
+		if opts.Compact {
+			col.lead, col.trail = dropRune(col.lead), dropRune(col.trail)
+		}
+	}
+
+	// Without an outer frame, there is nothing for the outermost cells to be spaced from.
+	if !b.outer && ncols > 0 {
+		b.cols[0].lead, b.cols[ncols-1].trail = "", ""
+	}
+
+	for _, row := range rows {
+		for i, cell := range row {
+			col := &b.cols[i]
+			width, widest := measureCell(cell)
+			if col.indent {
+				widest = indentedWidest(cell)
+			}
+			if width > col.width {
+				col.width = width
+			}
+			if widest > col.minWidth {
+				col.minWidth = widest
+			}
+		}
+	}
+
+	for _, cw := range opts.ColWidths {
+		if cw.Col >= ncols {
+			continue
+		}
+		col := &b.cols[cw.Col]
+		if cw.Max > 0 && col.width > cw.Max {
+			col.width = cw.Max
+			if col.width < col.minWidth {
+				col.width = col.minWidth
+			}
+		}
+		if col.width < cw.Min {
+			col.width = cw.Min
+		}
+		if col.minWidth < cw.Min {
+			col.minWidth = cw.Min
+		}
+	}
+	return b
+}
+
+// indentedWidest returns the greatest display width of the indent of a line of cell s and the widest grapheme
+// cluster that follows it, which no wrapping of that line after its indent can be narrower than.
+func indentedWidest(s string) int {
+	widest := 0
+	for _, line := range cellLines(s) {
+		indent, rest := splitIndent(line)
+		_, w := measure(rest)
+		if w += displayWidth(indent); w > widest {
+			widest = w
+		}
+	}
+	return widest
+}
+
+// width returns the display width of b, including its dividers.
+func (b *box) width() int {
+	width := len(b.cols) - 1
+	if b.outer {
+		width += 2
+	}
+	for i := range b.cols {
+		width += b.cols[i].span()
+	}
+	return width
+}
+
+// border returns a horizontal border line drawn with the glyphs of bb, joined at each column boundary. Without
+// an outer frame, the line has no ends.
+func (b *box) border(bb boxBorder) string {
+	key := bb
+	if line, ok := b.borders[key]; ok {
+		return line
+	}
+	if !b.outer {
+		bb.left, bb.right = "", ""
+	}
+
+	var sb strings.Builder
+	sb.Grow(b.width()*len(bb.fill) + 1)
+	sb.WriteString(bb.left)
+	for i := range b.cols {
+		if i > 0 {
+			sb.WriteString(bb.join)
+		}
+		for n := b.cols[i].span(); n > 0; n-- {
+			sb.WriteString(bb.fill)
+		}
+	}
+	sb.WriteString(bb.right)
+	sb.WriteByte('\n')
+
+	if b.borders == nil {
+		b.borders = make(map[boxBorder]string)
+	}
+	b.borders[key] = sb.String()
+	return sb.String()
+}
+
+// row returns the lines containing the cells of row, each surrounded by div. Cells with more than one line, or
+// wider than their columns and so wrapped, take up as many lines as the row needs, with shorter cells placed
+// within the row per b.valign. If sgr is not empty, the text of each cell, including its
+// alignment, is colored with it.
+func (b *box) row(row []string, div, sgr string) string {
+	// Each line is measured once, as it is wrapped, and its width kept for its alignment. The indent kept at the
+	// start of a line of an indent column is measured separately.
+	type line struct {
+		indent, text       string
+		indentWidth, width int
+	}
+
+	lines := make([][]line, len(b.cols))
+	height := 1
+	for i, col := range b.cols {
+		if i >= len(row) {
+			continue
+		}
+		for _, text := range cellLines(row[i]) {
+			var indent string
+			if col.indent {
+				indent, text = splitIndent(text)
+			}
+			iw := displayWidth(indent)
+			if width := displayWidth(text); iw+width <= col.width {
+				lines[i] = append(lines[i], line{indent, text, iw, width})
+				continue
+			}
+			for _, text := range wrapText(text, col.width-iw) {
+				lines[i] = append(lines[i], line{indent, text, iw, displayWidth(text)})
+			}
+		}
+		if len(lines[i]) > height {
+			height = len(lines[i])
+		}
+	}
+
+	offsets := make([]int, len(lines))
+	for i := range lines {
+		offsets[i] = valignOffset(len(lines[i]), height, b.valign)
+	}
+
+	var sb strings.Builder
+	for h := 0; h < height; h++ {
+		if b.outer {
+			sb.WriteString(div)
+		}
+		for i, col := range b.cols {
+			var l line
+			if n := h - offsets[i]; n >= 0 && n < len(lines[i]) {
+				l = lines[i][n]
+			}
+
+			if i > 0 {
+				sb.WriteString(div)
+			}
+			text := l.indent + padCell(l.text, l.width, col.width-l.indentWidth, col.align, b.pad)
+			if !b.outer && i == len(b.cols)-1 {
+				// Without a frame to align, the last cell needs no trailing padding, nor space before it if
+				// it is empty.
+				if text = strings.TrimRight(text, string(b.pad)); text == "" {
+					continue
+				}
+			}
+			sb.WriteString(col.lead)
+			sb.WriteString(colorize(text, sgr))
+			sb.WriteString(col.trail)
+		}
+		if b.outer {
+			sb.WriteString(div)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// fit narrows the columns of b, widest first, until the whole box is no wider than width. No column is narrowed
+// to less than its widest cluster, or a single cell, since its cells could not be wrapped to fit it and every line
+// of the box must be as wide as its borders. A box with many columns may therefore still be wider than width.
+func (b *box) fit(width int) {
+	for total := b.width(); total > width; total-- {
+		var widest *boxColumn
+		for i := range b.cols {
+			col := &b.cols[i]
+			if col.width > 1 && col.width > col.minWidth && (widest == nil || col.width > widest.width) {
+				widest = col
+			}
+		}
+		if widest == nil {
+			return
+		}
+		widest.width--
+	}
+}
+
+// writeBox writes rows to out as a table drawn with box-drawing characters. If opts.Header or opts.Footer is set,
+// the first or last row, respectively, is drawn with heavy borders. It returns the widths of the box's columns.
+func writeBox(out io.Writer, rows [][]string, opts *Options) []int {
+	if opts.Flags&tabwriter.DiscardEmptyColumns != 0 {
+		rows = discardEmptyColumns(rows)
+	}
+
+	b := newBox(rows, opts)
+	if opts.Width > 0 {
+		// Indented lines have that much less room.
+		b.fit(opts.Width - opts.Indent)
+	}
+
+	heavyHeader := opts.HeaderStyle == "" || opts.HeaderStyle == "heavy"
+	heavy := func(n int) bool {
+		return (opts.Header && heavyHeader && n == 0) || (opts.Footer && n == len(rows)-1)
+	}
+
+	style := boxStyles[opts.Style]
+
+	// A header that is not heavy is set apart from the rows below it by a double or light rule instead.
+	headerRule := style.heavyLight
+	switch opts.HeaderStyle {
+	case "double":
+		headerRule = style.double
+	case "light":
+		headerRule = style.sep
+	}
+
+	for n, row := range rows {
+		switch {
+		case opts.repeatsHeader(n, len(rows)) && heavyHeader:
+			io.WriteString(out, b.border(style.lightHeavy))
+			io.WriteString(out, b.row(rows[0], style.heavyDiv, opts.rowColor(0, len(rows))))
+			io.WriteString(out, b.border(headerRule))
+		case opts.repeatsHeader(n, len(rows)):
+			io.WriteString(out, b.border(style.sep))
+			io.WriteString(out, b.row(rows[0], style.div, opts.rowColor(0, len(rows))))
+			io.WriteString(out, b.border(headerRule))
+		case n == 0 && !b.outer:
+		case n == 0:
+			top := b.border(style.top)
+			if heavy(n) {
+				top = b.border(style.heavyTop)
+			}
+			if opts.TitleInline && opts.Title != "" {
+				top = spliceTitle(top, opts.Title, opts.Ellipsis)
+			}
+			io.WriteString(out, top)
+		case heavy(n-1) && heavy(n):
+			io.WriteString(out, b.border(style.heavySep))
+		case heavy(n - 1):
+			io.WriteString(out, b.border(style.heavyLight))
+		case heavy(n):
+			io.WriteString(out, b.border(style.lightHeavy))
+		case n == 1 && opts.Header:
+			io.WriteString(out, b.border(headerRule))
+		case opts.rowLine(n):
+			io.WriteString(out, b.border(style.sep))
+		}
+
+		sgr := opts.rowColor(n, len(rows))
+		if heavy(n) {
+			io.WriteString(out, b.row(row, style.heavyDiv, sgr))
+		} else {
+			io.WriteString(out, b.row(row, style.div, sgr))
+		}
+	}
+
+	switch {
+	case !b.outer:
+	case heavy(len(rows) - 1):
+		io.WriteString(out, b.border(style.heavyBottom))
+	default:
+		io.WriteString(out, b.border(style.bottom))
+	}
+
+	widths := make([]int, len(b.cols))
+	for i := range b.cols {
+		widths[i] = b.cols[i].width
+	}
+	return widths
+}
+
+// spliceTitle returns the top border line border with title, bracketed, centered over the line between its
+// corners. The title is truncated, ending in ellipsis, if it does not fit, and omitted if there is no room for
+// any of it.
+func spliceTitle(border, title, ellipsis string) string {
+	glyphs := []rune(strings.TrimSuffix(border, "\n"))
+	inner := len(glyphs) - 2 // Border glyphs are all one column wide.
+
+	const open, close = "[ ", " ]"
+	room := inner - len(open) - len(close)
+	if room < 1 {
+		return border
+	}
+
+	label := open + truncate(title, room, ellipsis) + close
+	width := displayWidth(label)
+	left := 1 + (inner-width)/2
+	return string(glyphs[:left]) + label + string(glyphs[left+width:]) + "\n"
+}
+
+// rowLine reports whether a separator is drawn above row n, which follows another data row. With RowLinesEvery,
+// separators are drawn only between groups of that many data rows; otherwise, RowLines draws them between all
+// rows.
+func (opts *Options) rowLine(n int) bool {
+	if opts.RowLinesEvery > 0 {
+		if opts.Header {
+			n--
+		}
+		return n%opts.RowLinesEvery == 0
+	}
+	return opts.RowLines
+}
+
+// dropRune returns s without its first rune.
+func dropRune(s string) string {
+	_, size := utf8.DecodeRuneInString(s)
+	return s[size:]
+}
+
+// repeatsHeader reports whether the header is repeated above row n of a table of nrows rows: before every
+// RepeatHeader data rows after the first, save for the footer.
+func (opts *Options) repeatsHeader(n, nrows int) bool {
+	if opts.RepeatHeader <= 0 || !opts.Header || n <= 1 || (opts.Footer && n == nrows-1) {
+		return false
+	}
+	return (n-1)%opts.RepeatHeader == 0
+}
+
+// discardEmptyColumns returns rows without the columns in which every cell is empty.
+func discardEmptyColumns(rows [][]string) [][]string {
+	empty := make([]bool, len(columnWidths(rows)))
+	for i := range empty {
+		empty[i] = true
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if cell != "" {
+				empty[i] = false
+			}
+		}
+	}
+
+	kept := make([][]string, len(rows))
+	for n, row := range rows {
+		for i, cell := range row {
+			if !empty[i] {
+				kept[n] = append(kept[n], cell)
+			}
+		}
+	}
+	return kept
+}
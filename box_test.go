@@ -0,0 +1,490 @@
+package ftable
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// checkAligned fails t unless every line of out, a box, has the same display width, and the dividers and
+// junctions of each line all fall on those of the lines with the most of them.
+func checkAligned(t *testing.T, out string) {
+	t.Helper()
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	for n, line := range lines {
+		if w, want := displayWidth(line), displayWidth(lines[0]); w != want {
+			t.Errorf("line %d is %d wide, want %d:\n%s", n+1, w, want, out)
+			return
+		}
+	}
+	checkDividers(t, out)
+}
+
+// checkDividers fails t unless the dividers and junctions of each line of out, a box, all fall on those of the
+// lines with the most of them. Unlike checkAligned, it allows lines of a frameless box to end early.
+func checkDividers(t *testing.T, out string) {
+	t.Helper()
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	var most []int
+	for _, line := range lines {
+		if cols := dividerColumns(line); len(cols) > len(most) {
+			most = cols
+		}
+	}
+
+	for n, line := range lines {
+		for _, col := range dividerColumns(line) {
+			if !hasColumn(most, col) {
+				t.Errorf("line %d has a divider at column %d, not at any of %v:\n%s", n+1, col, most, out)
+				return
+			}
+		}
+	}
+}
+
+// dividerColumns returns the display columns of line holding box-drawing characters other than horizontal lines,
+// or ASCII box dividers and junctions.
+func dividerColumns(line string) []int {
+	var cols []int
+	col := 0
+	for i := 0; i < len(line); {
+		if skip := escapeLen(line[i:]); skip > 0 {
+			i += skip
+			continue
+		}
+		size, width := nextCluster(line[i:])
+		switch r := []rune(line[i : i+size])[0]; {
+		case strings.ContainsRune("─━═┄┅┈┉╌╍-=", r):
+		case r >= 0x2500 && r <= 0x257f, r == '|', r == '+':
+			cols = append(cols, col)
+		}
+		col += width
+		i += size
+	}
+	return cols
+}
+
+// goldenInput is the input of the golden box tests: a header, two data rows, and a footer.
+const goldenInput = "name\tqty\tprice\napple\t3\t1.25\nwatermelon\t12\t0.5\ntotal\t15\t\n"
+
+func TestBoxGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		input  string
+		opts   Options
+	}{
+		{"box.golden", goldenInput, Options{Box: true}},
+		{"box-header.golden", goldenInput, Options{Box: true, Header: true}},
+		{"box-footer.golden", goldenInput, Options{Box: true, Footer: true}},
+		{"box-header-footer.golden", goldenInput, Options{Box: true, Header: true, Footer: true}},
+		{"box-ascii.golden", goldenInput, Options{Box: true, Header: true, Footer: true, RowLines: true, Style: StyleASCII}},
+		{"box-title.golden", goldenInput, Options{Box: true, Header: true, Title: "Fruit", TitleInline: true}},
+		{"box-title-long.golden", goldenInput, Options{Box: true, Header: true, Title: "A title much wider than the table it is drawn in", TitleInline: true}},
+		{"box-frameless.golden", goldenInput, Options{Box: true, Header: true, Footer: true, NoOuter: true}},
+		{"box-frameless-rowlines.golden", goldenInput, Options{Box: true, Header: true, RowLines: true, NoOuter: true}},
+		{"box-compact.golden", goldenInput, Options{Box: true, Header: true, Footer: true, Compact: true}},
+		{"box-compact-padded.golden", goldenInput, Options{Box: true, Header: true, Compact: true, Padding: 3}},
+		{"box-padded.golden", goldenInput, Options{Box: true, Header: true, Padding: 3}},
+		{"box-header-heavy.golden", goldenInput, Options{Box: true, Header: true, Footer: true, HeaderStyle: "heavy"}},
+		{"box-header-double.golden", goldenInput, Options{Box: true, Header: true, Footer: true, HeaderStyle: "double"}},
+		{"box-header-light.golden", goldenInput, Options{Box: true, Header: true, Footer: true, HeaderStyle: "light"}},
+		{"box-header-double-rounded.golden", goldenInput, Options{Box: true, Header: true, HeaderStyle: "double", Style: StyleRounded}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			got, err := RenderString(tt.input, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.golden, got)
+			if tt.opts.NoOuter {
+				checkDividers(t, got)
+				if strings.Contains(got, " \n") {
+					t.Errorf("frameless box has trailing spaces:\n%s", got)
+				}
+			} else {
+				checkAligned(t, got)
+			}
+			if tt.opts.Style == StyleASCII {
+				for i := 0; i < len(got); i++ {
+					if got[i] >= utf8.RuneSelf {
+						t.Fatalf("ASCII box has byte %#x at offset %d", got[i], i)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBoxWideCharacters(t *testing.T) {
+	tests := []struct {
+		name, in string
+	}{
+		{"cjk", "name\tcity\n張偉\t北京\nAlice\tParis\n"},
+		{"mixed in a cell", "id\tnote\n1\tok 完了\n22\tpending\n"},
+		{"fullwidth", "ＡＢＣ\tx\nabc\tyy\n"},
+		{"emoji", "status\tname\n✅\tbuild\n👍🏽\treview\n👨‍👩‍👧‍👦\tfamily\n🇯🇵\tflag\nok\tplain\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(tt.in, Options{Box: true, Header: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkAligned(t, got)
+		})
+	}
+}
+
+func TestBoxColoredCells(t *testing.T) {
+	tests := []struct {
+		name, colored, plain string
+	}{
+		{"cell", "a\t\x1b[31mred\x1b[0m\nbb\tc\n", "a\tred\nbb\tc\n"},
+		{"longest cell", "\x1b[1;32mgreen and bold\x1b[0m\tx\nshort\ty\n", "green and bold\tx\nshort\ty\n"},
+		{"part of a cell", "ok \x1b[33mwarn\x1b[39m done\t1\n", "ok warn done\t1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(tt.colored, Options{Box: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := RenderString(tt.plain, Options{Box: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkAligned(t, got)
+			if stripANSI(got) != want {
+				t.Errorf("colored box, without its colors:\n%s\nwant:\n%s", stripANSI(got), want)
+			}
+		})
+	}
+}
+
+// separatorLines returns the 1-based numbers of the lines of out, a box, that separate two light rows.
+func separatorLines(out string) []int {
+	var lines []int
+	for n, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "├") {
+			lines = append(lines, n+1)
+		}
+	}
+	return lines
+}
+
+func TestBoxRowLinesEvery(t *testing.T) {
+	var in strings.Builder
+	in.WriteString("n\n")
+	for n := 1; n <= 12; n++ {
+		fmt.Fprintf(&in, "%d\n", n)
+	}
+
+	rowLines, err := RenderString(in.String(), Options{Box: true, Header: true, RowLines: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		every int
+		want  []int
+	}{
+		// The header's rule is line 3, and the first data row is line 4.
+		{1, separatorLines(rowLines)},
+		{5, []int{9, 15}},
+		{12, nil},
+		{100, nil},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.every), func(t *testing.T) {
+			got, err := RenderString(in.String(), Options{Box: true, Header: true, RowLinesEvery: tt.every})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lines := separatorLines(got); fmt.Sprint(lines) != fmt.Sprint(tt.want) {
+				t.Errorf("separators on lines %v, want %v:\n%s", lines, tt.want, got)
+			}
+			if tt.every == 1 && got != rowLines {
+				t.Errorf("got:\n%s\nwant the same as RowLines:\n%s", got, rowLines)
+			}
+			checkAligned(t, got)
+		})
+	}
+}
+
+func TestBoxShortHeader(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{
+			name: "fewer cells",
+			in:   "id\nalpha\tbeta\tgamma\nx\ty\tz\n",
+			want: "┏━━━━━━━┳━━━━━━┳━━━━━━━┓\n┃ id    ┃      ┃       ┃\n┡━━━━━━━╇━━━━━━╇━━━━━━━┩\n" +
+				"│ alpha │ beta │ gamma │\n│ x     │ y    │ z     │\n└───────┴──────┴───────┘\n",
+		},
+		{
+			name: "narrower cells",
+			in:   "a\tb\nlonger\twider\n",
+			want: "┏━━━━━━━━┳━━━━━━━┓\n┃ a      ┃ b     ┃\n┡━━━━━━━━╇━━━━━━━┩\n│ longer │ wider │\n└────────┴───────┘\n",
+		},
+		{
+			name: "empty",
+			in:   "\nx\ty\n",
+			want: "┏━━━┳━━━┓\n┃   ┃   ┃\n┡━━━╇━━━┩\n│ x │ y │\n└───┴───┘\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(tt.in, Options{Box: true, Header: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+			checkAligned(t, got)
+		})
+	}
+}
+
+func TestBoxRowLinesWidth(t *testing.T) {
+	tests := []struct {
+		name, in string
+	}{
+		{"differing lengths", "a\nbbbbbbbb\tc\ndd\teeeeeeeeeeee\tf\n\ng\n"},
+		{"wide characters", "漢字\tx\nabc\t日本語\n"},
+		{"colors", "\x1b[31mred\x1b[0m\tx\nplain text\ty\n"},
+		{"multiline cells", "one\vtwo\vthree\tx\nfour\ty\vz\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, opts := range []Options{
+				{Box: true, RowLines: true},
+				{Box: true, RowLines: true, Header: true, Footer: true},
+			} {
+				got, err := RenderString(tt.in, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				lines := strings.Split(got, "\n")
+				if seps, want := len(separatorLines(got)), len(splitRows([]byte(tt.in)))-1; !opts.Header && seps != want {
+					t.Errorf("%d separators, want %d:\n%s", seps, want, got)
+				}
+				for _, n := range separatorLines(got) {
+					if w, want := displayWidth(lines[n-1]), displayWidth(lines[n]); w != want {
+						t.Errorf("separator on line %d is %d wide, but the row below it is %d:\n%s", n, w, want, got)
+					}
+				}
+				checkAligned(t, got)
+			}
+		})
+	}
+}
+
+func TestBoxMultilineCells(t *testing.T) {
+	tests := []struct {
+		name, in string
+		opts     Options
+		want     string
+	}{
+		{
+			name: "between one-line cells",
+			in:   "a\tone\vtwo\tc\nd\te\tf\n",
+			opts: Options{Box: true},
+			want: "┌───┬─────┬───┐\n│ a │ one │ c │\n│   │ two │   │\n│ d │ e   │ f │\n└───┴─────┴───┘\n",
+		},
+		{
+			name: "with rowlines",
+			in:   "a\tone\vtwo\tc\nd\te\tf\n",
+			opts: Options{Box: true, RowLines: true},
+			want: "┌───┬─────┬───┐\n│ a │ one │ c │\n│   │ two │   │\n├───┼─────┼───┤\n│ d │ e   │ f │\n└───┴─────┴───┘\n",
+		},
+		{
+			name: "last cell",
+			in:   "x\tfirst\vsecond line\n",
+			opts: Options{Box: true},
+			want: "┌───┬─────────────┐\n│ x │ first       │\n│   │ second line │\n└───┴─────────────┘\n",
+		},
+		{
+			name: "in the header",
+			in:   "name\tunit\vprice\napple\t3\n",
+			opts: Options{Box: true, Header: true},
+			want: "┏━━━━━━━┳━━━━━━━┓\n┃ name  ┃ unit  ┃\n┃       ┃ price ┃\n┡━━━━━━━╇━━━━━━━┩\n│ apple │ 3     │\n└───────┴───────┘\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(tt.in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoxVAlign(t *testing.T) {
+	const in = "x\t1\v2\v3\ty\n"
+	const top, bottom = "┌───┬───┬───┐\n", "└───┴───┴───┘\n"
+	tests := []struct {
+		valign, want string
+	}{
+		{"", top + "│ x │ 1 │ y │\n│   │ 2 │   │\n│   │ 3 │   │\n" + bottom},
+		{"top", top + "│ x │ 1 │ y │\n│   │ 2 │   │\n│   │ 3 │   │\n" + bottom},
+		{"middle", top + "│   │ 1 │   │\n│ x │ 2 │ y │\n│   │ 3 │   │\n" + bottom},
+		{"bottom", top + "│   │ 1 │   │\n│   │ 2 │   │\n│ x │ 3 │ y │\n" + bottom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.valign, func(t *testing.T) {
+			got, err := RenderString(in, Options{Box: true, VAlign: tt.valign})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := RenderString(in, Options{Box: true, VAlign: "center"}); err == nil {
+		t.Error("VAlign center: got no error")
+	}
+}
+
+func TestBoxShiftOut(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{
+			name: "inside a cell",
+			in:   "a\x0eb\tc\n",
+			want: "┌────┬───┐\n│ a\x0eb │ c │\n└────┴───┘\n",
+		},
+		{
+			name: "ending a cell",
+			in:   "a\tb\x0e\ncc\td\n",
+			want: "┌────┬───┐\n│ a  │ b\x0e │\n│ cc │ d │\n└────┴───┘\n",
+		},
+		{
+			name: "alone",
+			in:   "\x0e\tx\n",
+			want: "┌──┬───┐\n│ \x0e │ x │\n└──┴───┘\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(tt.in, Options{Box: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+			checkAligned(t, got)
+		})
+	}
+}
+
+// BenchmarkBoxBorder draws the border between the rows of a box 10,000 times, as RowLines does for a table of
+// 10,000 rows, with and without borders cached.
+func BenchmarkBoxBorder(b *testing.B) {
+	opts := &Options{Box: true}
+	if err := opts.init(); err != nil {
+		b.Fatal(err)
+	}
+	bx := newBox(splitRows([]byte(benchInput(10, 8))), opts)
+	sep := boxStyles[StyleLight].sep
+
+	benchmarks := []struct {
+		name  string
+		reset bool
+	}{
+		{"cached", false},
+		{"uncached", true},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bx.borders = nil
+				for n := 0; n < 10000; n++ {
+					if bm.reset {
+						bx.borders = nil
+					}
+					if line := bx.border(sep); !strings.HasPrefix(line, sep.left) {
+						b.Fatalf("border = %q, want it to begin with %q", line, sep.left)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRepeatsHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   Options
+		nrows  int
+		repeat []int
+	}{
+		{"every 2", Options{Header: true, RepeatHeader: 2}, 8, []int{3, 5, 7}},
+		{"every 3", Options{Header: true, RepeatHeader: 3}, 8, []int{4, 7}},
+		{"every row", Options{Header: true, RepeatHeader: 1}, 4, []int{2, 3}},
+		{"not before the footer", Options{Header: true, Footer: true, RepeatHeader: 2}, 6, []int{3}},
+		{"more than the rows", Options{Header: true, RepeatHeader: 10}, 8, nil},
+		{"no header", Options{RepeatHeader: 2}, 8, nil},
+		{"off", Options{Header: true}, 8, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for n := 0; n < tt.nrows; n++ {
+				if tt.opts.repeatsHeader(n, tt.nrows) {
+					got = append(got, n)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.repeat) {
+				t.Errorf("repeated above rows %v, want %v", got, tt.repeat)
+			}
+		})
+	}
+}
+
+func TestRenderRepeatHeader(t *testing.T) {
+	in := "n\n1\n2\n3\n4\n5\nsum\n"
+	got, err := RenderString(in, Options{Header: true, Footer: true, RepeatHeader: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "n\n1\n2\nn\n3\n4\nn\n5\nsum\n"; got != want {
+		t.Errorf("text: got %q, want %q", got, want)
+	}
+
+	got, err = RenderString(in, Options{Box: true, Header: true, Footer: true, RepeatHeader: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAligned(t, got)
+	want := "┏━━━━━┓\n" +
+		"┃ n   ┃\n" +
+		"┡━━━━━┩\n" +
+		"│ 1   │\n" +
+		"│ 2   │\n" +
+		"┢━━━━━┪\n" +
+		"┃ n   ┃\n" +
+		"┡━━━━━┩\n" +
+		"│ 3   │\n" +
+		"│ 4   │\n" +
+		"┢━━━━━┪\n" +
+		"┃ n   ┃\n" +
+		"┡━━━━━┩\n" +
+		"│ 5   │\n" +
+		"┢━━━━━┪\n" +
+		"┃ sum ┃\n" +
+		"┗━━━━━┛\n"
+	if got != want {
+		t.Errorf("box: got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,22 @@
+package ftable
+
+import "strings"
+
+// checkMarks are the marks written in place of truthy and falsy cells by Checks, and asciiCheckMarks those
+// written in boxes of StyleASCII.
+var (
+	checkMarks      = [2]string{"✓", "✗"}
+	asciiCheckMarks = [2]string{"Y", "N"}
+)
+
+// checkMark returns the mark for s, written by Checks with marks: the first if s is truthy, such as "yes" or
+// "true", or the second if it is falsy, such as "no" or "false". Otherwise, s is returned unchanged.
+func checkMark(s string, marks [2]string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "t", "yes", "y", "on", "1":
+		return marks[0]
+	case "false", "f", "no", "n", "off", "0":
+		return marks[1]
+	}
+	return s
+}
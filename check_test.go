@@ -0,0 +1,83 @@
+package ftable
+
+import "testing"
+
+func TestCheckMark(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"true", "✓"},
+		{"t", "✓"},
+		{"yes", "✓"},
+		{"y", "✓"},
+		{"on", "✓"},
+		{"1", "✓"},
+		{"TRUE", "✓"},
+		{" Yes ", "✓"},
+		{"false", "✗"},
+		{"f", "✗"},
+		{"no", "✗"},
+		{"n", "✗"},
+		{"off", "✗"},
+		{"0", "✗"},
+		{"No", "✗"},
+		{"", ""},
+		{"maybe", "maybe"},
+		{"2", "2"},
+		{"yess", "yess"},
+	}
+	for _, tt := range tests {
+		if got := checkMark(tt.s, checkMarks); got != tt.want {
+			t.Errorf("checkMark(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+	if got := checkMark("yes", asciiCheckMarks); got != "Y" {
+		t.Errorf("ASCII checkMark(yes) = %q, want Y", got)
+	}
+	if got := checkMark("no", asciiCheckMarks); got != "N" {
+		t.Errorf("ASCII checkMark(no) = %q, want N", got)
+	}
+}
+
+func TestRenderChecks(t *testing.T) {
+	const in = "feature\tlinux\twindows\nboxes\tyes\tno\ncolor\ttrue\tpartly\nheader\t1\t0\n"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			"box", Options{Box: true, Header: true, Checks: []int{1, 2}},
+			"┏━━━━━━━━━┳━━━━━━━┳━━━━━━━━━┓\n" +
+				"┃ feature ┃ linux ┃ windows ┃\n" +
+				"┡━━━━━━━━━╇━━━━━━━╇━━━━━━━━━┩\n" +
+				"│ boxes   │   ✓   │    ✗    │\n" +
+				"│ color   │   ✓   │ partly  │\n" +
+				"│ header  │   ✓   │    ✗    │\n" +
+				"└─────────┴───────┴─────────┘\n",
+		},
+		{
+			"ascii", Options{Box: true, Header: true, Style: StyleASCII, Checks: []int{1}},
+			"+=========+=======+=========+\n" +
+				"| feature | linux | windows |\n" +
+				"+=========+=======+=========+\n" +
+				"| boxes   |   Y   | no      |\n" +
+				"| color   |   Y   | partly  |\n" +
+				"| header  |   Y   | 0       |\n" +
+				"+---------+-------+---------+\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+			checkAligned(t, got)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultConfigPath returns the path of the config file read if no -config flag is given:
+// $XDG_CONFIG_HOME/ftable/config, or ~/.config/ftable/config if $XDG_CONFIG_HOME is unset. It returns an empty
+// string if neither directory is known.
+func defaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "ftable", "config")
+}
+
+// configFlag returns the value of the last -config flag in args, if any. Since the config file is read before
+// any flags are parsed, args are parsed for it ahead of time by a throwaway flag set declaring the same flags as
+// flag.CommandLine, so that the values of other flags are skipped just as flag.Parse skips them. Any errors are
+// left for flag.Parse to report.
+func configFlag(args []string) (path string, ok bool) {
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	flag.VisitAll(func(f *flag.Flag) {
+		v := &scanValue{}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok {
+			v.isBool = b.IsBoolFlag()
+		}
+		fs.Var(v, f.Name, "")
+	})
+	fs.Parse(args)
+
+	if f := fs.Lookup("config"); f != nil {
+		v := f.Value.(*scanValue)
+		return v.value, v.set
+	}
+	return "", false
+}
+
+// scanValue is a flag.Value that records the last value it was set to, for configFlag.
+type scanValue struct {
+	value       string
+	set, isBool bool
+}
+
+func (v *scanValue) Set(s string) error {
+	v.value, v.set = s, true
+	return nil
+}
+
+func (v *scanValue) String() string {
+	return v.value
+}
+
+func (v *scanValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// loadConfig sets the flags named in the config file at path, one "name = value" pair per line, as defaults for
+// those on the command line. Blank lines and lines starting with '#' are ignored. A boolean flag may be given
+// without a value to set it.
+func loadConfig(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, found := strings.Cut(line, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !found {
+			value = "true"
+		}
+		if name == "config" || flag.Lookup(name) == nil {
+			return fmt.Errorf("%s:%d: unrecognized flag %q", path, n, name)
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("%s:%d: invalid value %q for flag %s: %v", path, n, value, name, err)
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigFlag(t *testing.T) {
+	// configFlag parses with the flags of flag.CommandLine, which run declares.
+	defer func(fs *flag.FlagSet) { flag.CommandLine = fs }(flag.CommandLine)
+	flag.CommandLine = flag.NewFlagSet("ftable", flag.ContinueOnError)
+	flag.Bool("box", false, "")
+	flag.String("o", "", "")
+	flag.String("title", "", "")
+	flag.String("config", "", "")
+
+	tests := []struct {
+		args     []string
+		wantPath string
+		wantOK   bool
+	}{
+		{nil, "", false},
+		{[]string{"-box", "file.txt"}, "", false},
+		{[]string{"-config", "c.conf"}, "c.conf", true},
+		{[]string{"--config=c.conf", "-box"}, "c.conf", true},
+		{[]string{"-o", "out", "-config", "c.conf"}, "c.conf", true},
+		{[]string{"-title", "-config", "-box"}, "", false},
+		{[]string{"-box", "-config", "a.conf", "-config", "b.conf"}, "b.conf", true},
+		{[]string{"-box", "file.txt", "-config", "c.conf"}, "", false},
+		{[]string{"--", "-config", "c.conf"}, "", false},
+		{[]string{"-bogus", "-config", "c.conf"}, "", false},
+	}
+	for _, tt := range tests {
+		path, ok := configFlag(tt.args)
+		if path != tt.wantPath || ok != tt.wantOK {
+			t.Errorf("configFlag(%q) = %q, %v, want %q, %v", tt.args, path, ok, tt.wantPath, tt.wantOK)
+		}
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "ftable", "config")
+	if err := os.MkdirAll(filepath.Dir(config), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	const contents = "# defaults\nbox\n\nstyle = heavy\nempty = n/a\n"
+	if err := os.WriteFile(config, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(dir, "other.conf")
+	if err := os.WriteFile(other, []byte("box\nstyle=rounded\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want string // The top-left corner of the box, or "a" if none is drawn.
+	}{
+		{"config only", "", nil, "┏"},
+		{"env overrides config", "-style double", nil, "╔"},
+		{"command line overrides config", "", []string{"-style", "light"}, "┌"},
+		{"command line overrides env", "-style double", []string{"-style", "light"}, "┌"},
+		{"command line turns off config", "", []string{"-box=false"}, "a"},
+		{"named config", "", []string{"-o", "/dev/stdout", "-config", other}, "╭"},
+		{"config named by env", "-config " + other, nil, "╭"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := []string{"XDG_CONFIG_HOME=" + dir, "FTABLE_OPTS=" + tt.env}
+			out, stderr, code := runFtable(t, "a\tb\n\tc\n", env, tt.args...)
+			if code != exitOK {
+				t.Fatalf("exited with %d: %s", code, stderr)
+			}
+			if !strings.HasPrefix(out, tt.want) {
+				t.Errorf("got:\n%s\nwant it to begin with %q", out, tt.want)
+			}
+			if tt.want == "┏" && !strings.Contains(out, "n/a") {
+				t.Errorf("got:\n%s\nwant the config's -empty placeholder", out)
+			}
+		})
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.conf")
+	if err := os.WriteFile(bad, []byte("box\nbogus = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown flag", []string{"-config", bad}, "bad.conf:2: unrecognized flag \"bogus\""},
+		{"missing file", []string{"-config", filepath.Join(dir, "missing.conf")}, "error reading config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr, code := runFtable(t, "a\n", nil, tt.args...)
+			if code != exitError || !strings.Contains(stderr, tt.want) {
+				t.Errorf("exited with %d: %s\nwant %d and %q", code, stderr, exitError, tt.want)
+			}
+		})
+	}
+
+	// A missing default config file is not an error.
+	if _, stderr, code := runFtable(t, "a\n", []string{"XDG_CONFIG_HOME=" + dir}); code != exitOK {
+		t.Errorf("without a config file, exited with %d: %s", code, stderr)
+	}
+}
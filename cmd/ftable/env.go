@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// splitArgs splits s into arguments as a shell would, at unquoted whitespace. Single quotes preserve everything
+// between them, double quotes preserve everything but backslash escapes, and a backslash outside single quotes
+// escapes the character following it. Nothing else, such as variables or globs, is expanded.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg, escaped := false, false
+	var quote rune
+	for _, r := range s {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	switch {
+	case escaped:
+		return nil, errors.New("trailing backslash")
+	case quote != 0:
+		return nil, errors.New("unterminated quote")
+	case inArg:
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
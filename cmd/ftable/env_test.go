@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "-box -header", want: []string{"-box", "-header"}},
+		{in: "  -box\t\n-header  ", want: []string{"-box", "-header"}},
+		{in: `-title 'two words'`, want: []string{"-title", "two words"}},
+		{in: `-title "two \"quoted\" words"`, want: []string{"-title", `two "quoted" words`}},
+		{in: `-title 'back\slash'`, want: []string{"-title", `back\slash`}},
+		{in: `-title two\ words`, want: []string{"-title", "two words"}},
+		{in: `-empty ''`, want: []string{"-empty", ""}},
+		{in: `-d"|"x`, want: []string{"-d|x"}},
+		{in: `-title 'open`, wantErr: true},
+		{in: `-title open\`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := splitArgs(tt.in)
+		switch {
+		case tt.wantErr && err == nil:
+			t.Errorf("splitArgs(%q) = %q, want an error", tt.in, got)
+		case !tt.wantErr && err != nil:
+			t.Errorf("splitArgs(%q) = %v", tt.in, err)
+		case !reflect.DeepEqual(got, tt.want):
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEnvPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want string // The top-left corner of the box, or "a" if none is drawn.
+		has  string // Text the output must also contain.
+	}{
+		{"env only", "-box -style heavy", nil, "┏", ""},
+		{"command line overrides env", "-box -style heavy", []string{"-style", "double"}, "╔", ""},
+		{"command line turns off env", "-box -style heavy", []string{"-box=false"}, "a", ""},
+		{"command line adds to env", "-style heavy", []string{"-box"}, "┏", ""},
+		{"quoted env", `-box -empty 'n/a' -ellipsis "..."`, []string{"-maxcol", "4"}, "┌", "│ n/a │ x... │"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, stderr, code := runFtable(t, "a\tb\n\txxxxxx\n", []string{"FTABLE_OPTS=" + tt.env}, tt.args...)
+			if code != exitOK {
+				t.Fatalf("exited with %d: %s", code, stderr)
+			}
+			if !strings.HasPrefix(out, tt.want) || !strings.Contains(out, tt.has) {
+				t.Errorf("got:\n%s\nwant it to begin with %q and contain %q", out, tt.want, tt.has)
+			}
+		})
+	}
+
+	if _, _, code := runFtable(t, "a\n", []string{"FTABLE_OPTS=-box file.txt"}); code != exitError {
+		t.Errorf("FTABLE_OPTS with an argument exited with %d, want %d", code, exitError)
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// utf8Locale reports whether the locale set by the environment uses UTF-8, checking $LC_ALL, $LC_CTYPE, and
+// $LANG in order of precedence. If none of them is set, the locale is assumed to be UTF-8, since that is far more
+// likely than not of any terminal today.
+func utf8Locale() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			v = strings.ToLower(v)
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+	return true
+}
@@ -0,0 +1,744 @@
+// Command ftable reads all text from the files named on the command line, or stdin if none are given, and
+// passes it through a text/tabwriter to produce pretty columnar output. It will optionally wrap all output in box
+// drawing glyphs if the -box flag is set, with a header row when -header is passed in addition to -box (and a
+// footer row when -footer is). The -format flag selects an alternative markup output, such as a Markdown or
+// HTML table, in place of the tabwriter's. Gzip-compressed input, such as a .csv.gz file, is decompressed as it is
+// read.
+//
+// The -style flag selects the style in which boxes are drawn. Boxes are drawn with ASCII characters in place of
+// box-drawing characters if -ascii is set or, by default, if no -style is given and the locale named by $LC_ALL,
+// $LC_CTYPE, or $LANG is not a UTF-8 locale.
+//
+// The -headercolor, -bold-header, and -zebra flags color the header row and alternating data rows with ANSI
+// escape sequences, -highlight colors the greatest or least numbers in columns, -match colors the matches of a
+// regexp within cells, and the -link flag makes the cells of a column terminal hyperlinks. By default, output is
+// only colored and linked when writing to a terminal and $NO_COLOR is unset; -color always or -color never
+// overrides this.
+//
+// Default flags may be set in $FTABLE_OPTS, such as FTABLE_OPTS="-box -header -style rounded", and in a config
+// file of "name = value" lines, read from $XDG_CONFIG_HOME/ftable/config or the file named by -config. Flags in
+// $FTABLE_OPTS override those in the config file, and flags on the command line override both.
+//
+// If -box is set and the input is empty or contains only whitespace, ftable writes nothing and exits with status
+// 3.
+//
+// With -labels, each file named on the command line is formatted as a separate table, titled with its name, and
+// tables are separated by blank lines.
+//
+// With -check, ftable formats nothing, and instead reports each input row with a different number of fields than
+// the first to stderr, exiting with status 1 if there are any.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/nilium/ftable"
+)
+
+type tabFlags uint
+
+var flagBits = map[uint]string{
+	tabwriter.FilterHTML:          "filter-html",
+	tabwriter.StripEscape:         "strip-escape",
+	tabwriter.AlignRight:          "align-right",
+	tabwriter.DiscardEmptyColumns: "discard-empty",
+	tabwriter.TabIndent:           "tab-indent",
+	tabwriter.Debug:               "debug",
+}
+
+var flagNames = map[string]uint{}
+
+func init() {
+	for b, n := range flagBits {
+		flagNames[n] = b
+	}
+}
+
+func (t *tabFlags) Set(v string) error {
+	flags := uint(*t)
+	for _, name := range strings.Split(v, ",") {
+		if b, ok := flagNames[name]; ok {
+			flags |= b
+		} else {
+			return fmt.Errorf("unrecognized flag %q", name)
+		}
+	}
+	*t = tabFlags(flags)
+	return nil
+}
+
+type filterFlags []ftable.Filter
+
+func (f *filterFlags) Set(v string) error {
+	filter, err := ftable.ParseFilter(v)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, filter)
+	return nil
+}
+
+func (f *filterFlags) String() string {
+	return ""
+}
+
+type dateFlags []ftable.DateFormat
+
+func (d *dateFlags) Set(v string) error {
+	date, err := ftable.ParseDateFormat(v)
+	if err != nil {
+		return err
+	}
+	*d = append(*d, date)
+	return nil
+}
+
+func (d *dateFlags) String() string {
+	return ""
+}
+
+func (t *tabFlags) String() string {
+	flags := []string{}
+	ui := uint(*t)
+	for b, n := range flagBits {
+		if ui&b == b {
+			flags = append(flags, n)
+		}
+	}
+	sort.Strings(flags)
+	return strings.Join(flags, ",")
+}
+
+// eachInput calls fn with each named file, in order, or with stdin if no names are given. Files that cannot be
+// opened or read are reported to stderr (the file's name is carried by the *os.PathError) and skipped. It
+// returns false if any input failed.
+func eachInput(names []string, fn func(io.Reader) error) (ok bool) {
+	if len(names) == 0 {
+		if err := fn(os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "error reading from stdin: %v\n", err)
+			return false
+		}
+		return true
+	}
+
+	ok = true
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error opening input: %v\n", err)
+			ok = false
+			continue
+		}
+
+		if err = fn(f); err != nil {
+			fmt.Fprintf(os.Stderr, "error reading input: %v\n", err)
+			ok = false
+		}
+
+		if err = f.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "error closing input: %v\n", err)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// version is the version of ftable, set at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
+// Exit codes returned by run.
+const (
+	exitOK    = 0
+	exitError = 1
+	// exitEmpty is returned, with no output, when a box would be drawn around empty or whitespace-only input.
+	exitEmpty = 3
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run parses the command line, formats all input, and returns the process exit code.
+func run() (code int) {
+	var opts ftable.Options
+	var config, padchar, delimRE, aligns, cols, exclude, sortKeys, humanize, dehumanize, checks, collapse, colWidths, headerColor, zebraColors, color, style, groupChar, aggs, highlights, highlightColor, match, matchColor, fixed, output string
+	var zebra, boldHeader, grouping, trim, printVersion, verbose, widths, check, labels, nulFields, nulRecords, showControl bool
+	var flags tabFlags
+	var ascii bool
+
+	flag.BoolVar(&opts.Box, "box", false, "whether to box the output with box-drawing characters")
+	flag.BoolVar(&opts.Header, "header", false, "whether the first line of input is a header row, drawn as a header box with -box")
+	flag.BoolVar(&opts.Footer, "footer", false, "whether the last line of input is a footer row, drawn as a footer box with -box")
+	flag.StringVar(&color, "color", "auto", "`when` to color output: auto (when writing to a terminal and $NO_COLOR is unset), always, or never")
+	flag.BoolVar(&boldHeader, "bold-header", false, "whether to bold the text of the header row, in addition to any -headercolor, subject to -color")
+	flag.StringVar(&headerColor, "headercolor", "", "the `color` of the header row, subject to -color: a color name (e.g., bold or blue) or SGR parameters (e.g., 1;34)")
+	flag.BoolVar(&zebra, "zebra", false, "whether to color alternating data rows, subject to -color")
+	flag.StringVar(&highlights, "highlight", "", "a comma-separated `list` of columns in which to highlight the cells holding the greatest or least number, each a 1-based column and max or min, such as 2:max, subject to -color")
+	flag.StringVar(&highlightColor, "highlight-color", "", "the `color` of cells highlighted by -highlight (default: bold)")
+	flag.StringVar(&match, "match", "", "highlight every match of the `regexp` within cells, as grep --color does, subject to -color")
+	flag.StringVar(&matchColor, "match-color", "", "the `color` of matches highlighted by -match (default: bold red)")
+	flag.StringVar(&zebraColors, "zebracolors", ",dim", "a comma-separated `pair` of colors for alternating data rows with -zebra; an empty color leaves its rows uncolored")
+	flag.IntVar(&opts.Indent, "indent", 0, "indent every line of output by `n` spaces, other than with data formats such as csv and json")
+	flag.BoolVar(&opts.Center, "center", false, "whether to center output within the -width, which defaults to the terminal width; output as wide or wider is not centered")
+	flag.StringVar(&opts.HeaderStyle, "header-style", "heavy", "the `style` of the -header in a box: heavy, or double or light for light borders with a double or light line below it")
+	flag.IntVar(&opts.RepeatHeader, "repeat-header", 0, "repeat the -header before every `n` data rows after the first")
+	flag.BoolVar(&opts.NoOuter, "no-outer", false, "whether to draw boxes without their outer frame, keeping only inner dividers")
+	flag.BoolVar(&opts.Compact, "compact", false, "whether to draw boxes without the space around each cell")
+	flag.BoolVar(&opts.RowLines, "rowlines", false, "whether to insert row separators in box mode")
+	flag.IntVar(&opts.RowLinesEvery, "rowlines-every", 0, "insert row separators in box mode only after every `n` data rows; overrides -rowlines")
+	flag.StringVar(&style, "style", string(ftable.StyleLight), "the `style` of box borders: "+strings.Join(styleNames(), ", "))
+	flag.BoolVar(&ascii, "ascii", false, "whether to draw boxes with ASCII characters, as with -style ascii (default: true if the locale is not UTF-8 and no -style is given)")
+	flag.StringVar(&opts.Title, "title", "", "a `title` to print centered over the table")
+	flag.BoolVar(&labels, "labels", false, "whether to format each named file as a separate table, titled with its name in place of any -title")
+	flag.BoolVar(&opts.TitleInline, "title-inline", false, "whether to embed the -title in the top border of a box")
+	flag.StringVar(&opts.TitlePos, "title-pos", "top", "the `position` of the -title: top or bottom")
+	flag.IntVar(&opts.Width, "width", 0, "the maximum `width` of boxed output, wrapping cells to fit; 0 for no limit (default: the terminal width, if writing to one)")
+	flag.IntVar(&opts.MaxCol, "maxcol", 0, "the maximum `width` of a cell; wider cells are truncated with an ellipsis")
+	flag.StringVar(&colWidths, "colwidth", "", "a comma-separated `list` of column widths, each a 1-based column and a min-max range (either end of which may be omitted), such as 2:5-20; narrower columns are padded and wider ones truncated or, with -box, wrapped")
+	flag.StringVar(&opts.Ellipsis, "ellipsis", "", "the `string` ending truncated cells and titles (default: … if the locale is UTF-8, and ... otherwise)")
+	flag.StringVar(&sortKeys, "sort", "", "sort rows by a comma-separated `list` of 1-based columns, each after those before it, keeping any header and footer rows in place; each column may be followed by a for ascending or d for descending order, and n, v, or l for numeric, natural, or lexical comparison, such as 2d,1n")
+	flag.BoolVar(&opts.SortNumeric, "numeric", false, "whether -sort compares cells by numeric value")
+	flag.BoolVar(&opts.SortNatural, "natural", false, "whether -sort compares cells in natural order, so that item2 sorts before item10")
+	flag.StringVar(&humanize, "humanize", "", "a comma-separated `list` of 1-based columns of numbers of bytes to write as sizes, such as 1.5 KiB")
+	flag.StringVar(&dehumanize, "dehumanize", "", "a comma-separated `list` of 1-based columns of sizes, such as 1.5 KiB or 2M, to write as numbers of bytes")
+	flag.BoolVar(&opts.SizeSI, "si", false, "whether -humanize and -dehumanize use powers of 1000, such as kB, rather than of 1024, such as KiB")
+	flag.StringVar(&checks, "check-col", "", "a comma-separated `list` of 1-based columns of boolean values, such as yes and no, to write as centered check and cross marks, or Y and N with -ascii")
+	flag.BoolVar(&opts.IgnoreCase, "ignore-case", false, "whether -sort and -filter compare and match cells without regard to case")
+	flag.BoolVar(&opts.SortReverse, "reverse", false, "whether to reverse the order of -sort, so that it sorts in descending order")
+	flag.Var((*dateFlags)(&opts.Dates), "date", "rewrite the dates in a 1-based column with a Go time `layout`, such as 2:2006-01-02, leaving cells that are not dates unchanged; may be repeated")
+	flag.StringVar(&opts.DateInput, "date-in", "", "the Go time `layout` in which -date parses dates, before trying common layouts such as RFC 3339")
+	flag.Var((*filterFlags)(&opts.Filters), "filter", "keep only data rows whose 1-based column meets a `condition`: ~ matching a regexp, = equal to a value, or >, <, >=, or <= a number, such as 2~^foo or 3>=10; may be repeated, and rows must meet all conditions")
+	flag.IntVar(&opts.Link, "link", 0, "make the cells of the 1-based `column` terminal hyperlinks to their text, subject to -color")
+	flag.IntVar(&opts.LinkURL, "link-url", 0, "link the cells of the -link column to the URLs in the 1-based `column`, in place of their text")
+	flag.BoolVar(&opts.Unique, "unique", false, "whether to drop data rows that duplicate an earlier row")
+	flag.IntVar(&opts.UniqueBy, "unique-by", 0, "drop data rows that duplicate an earlier row in the 1-based `column`")
+	flag.IntVar(&opts.Head, "head", 0, "output only the first `n` data rows, after any -filter and -sort")
+	flag.IntVar(&opts.Tail, "tail", 0, "output only the last `n` data rows, after any -filter and -sort")
+	flag.IntVar(&opts.GroupBy, "groupby", 0, "group rows by the 1-based `column`, sorting by it after any -sort and blanking repeated keys; with -agg, each group is followed by a subtotal row")
+	flag.StringVar(&collapse, "collapse", "", "a comma-separated `list` of 1-based columns in which to blank each cell that repeats the one above it, after any -sort or -groupby")
+	flag.StringVar(&cols, "cols", "", "a comma-separated `list` of 1-based columns or column ranges (e.g., 1-3) to output, in order")
+	flag.StringVar(&exclude, "exclude", "", "a comma-separated `list` of 1-based columns or column ranges not to output, even if -cols names them")
+	flag.BoolVar(&trim, "trim", false, "whether to trim surrounding whitespace from each cell; the same as -trim-left -trim-right")
+	flag.BoolVar(&opts.TrimLeft, "trim-left", false, "whether to trim leading whitespace from each cell")
+	flag.BoolVar(&opts.TrimRight, "trim-right", false, "whether to trim trailing whitespace from each cell")
+	flag.BoolVar(&opts.KeepIndent, "keep-indent", false, "whether to keep the leading whitespace of the first column through trimming, alignment, and wrapping")
+	flag.BoolVar(&opts.Transpose, "transpose", false, "whether to swap rows and columns; -header then treats the first input column as the header")
+	flag.StringVar(&aggs, "agg", "", "a comma-separated `list` of aggregates to add as a footer row, each a 1-based column and a function (sum, avg, min, max, or count), such as 2:sum,3:avg")
+	flag.BoolVar(&grouping, "grouping", false, "whether to insert thousands separators into numbers in numeric columns")
+	flag.StringVar(&groupChar, "grouping-char", ",", "the `char` separating groups of digits with -grouping")
+	flag.BoolVar(&opts.Number, "number", false, "whether to add a first column numbering the rows, other than any header and footer")
+	flag.IntVar(&opts.NumberFrom, "number-from", 1, "the `number` of the first row numbered by -number")
+	flag.IntVar(&opts.MinWidth, "minwidth", 0, "the minimum `width` of a column in bytes")
+	flag.IntVar(&opts.TabWidth, "tabwidth", 8, "the `width` of a tab in bytes")
+	flag.IntVar(&opts.Padding, "padding", 1, "`padding`")
+	flag.StringVar(&padchar, "padchar", " ", "the padding `char` to use; it may be any single character with -box, but must be a single byte otherwise")
+	flag.StringVar(&opts.RecordSep, "rs", "", "the `separator` between input rows, in place of line endings, which then break lines within cells")
+	flag.BoolVar(&nulRecords, "Z", false, "whether input rows are separated by NUL bytes, as with -rs, such as the output of find -print0")
+	flag.BoolVar(&nulFields, "z", false, "whether input columns are separated by NUL bytes, as with -d")
+	flag.StringVar(&opts.Delim, "d", "", "the `delimiter` separating input columns, in place of tabs, or auto to detect a tab, comma, semicolon, or pipe from the first lines of input")
+	flag.StringVar(&delimRE, "D", "", "a `regexp` matching the separator between input columns; overrides -d")
+	flag.StringVar(&fixed, "fixed", "", "parse input as fixed-width columns: a comma-separated `list` of column widths, such as 8,12,6, with any text past the last in one more column, or auto to infer columns from the positions that are spaces on every line")
+	flag.StringVar(&opts.From, "from", "", "the input `format`: markdown to parse a Markdown table, in place of delimited columns or -csv")
+	flag.BoolVar(&opts.CSV, "csv", false, "whether to parse input as CSV; -d sets the field separator, if given")
+	flag.StringVar(&aligns, "align", "", "a comma-separated `list` of column alignments (l, r, or c); overrides align-right")
+	flag.BoolVar(&opts.PadRows, "pad-rows", false, "whether to pad rows with empty cells to the length of the longest row")
+	flag.BoolVar(&opts.Strict, "strict", false, "whether to fail if any row has a different number of fields than the first")
+	flag.StringVar(&opts.Empty, "empty", "", "a placeholder `string`, such as - or N/A, to write in place of empty data cells")
+	flag.StringVar(&opts.VAlign, "valign", "top", "the vertical `alignment` of cells in rows with multi-line cells: top, middle, or bottom")
+	flag.BoolVar(&opts.AutoNum, "autonum", false, "whether to right-align columns whose non-empty cells are all numbers")
+	flag.BoolVar(&opts.SkipBlank, "skip-blank", false, "whether to drop input lines that are empty or only spaces")
+	flag.StringVar(&opts.Comment, "comment", "", "drop input lines beginning with `prefix`, such as #")
+	flag.BoolVar(&showControl, "show-control", false, "whether to show control characters in cells as Unicode control pictures, such as ␇, or in caret notation, such as ^G, if the locale is not UTF-8 or with -ascii")
+	flag.BoolVar(&opts.StripColor, "stripcolor", false, "whether to remove ANSI color escape sequences from input")
+	flag.StringVar(&opts.Format, "format", "text", "the output `format`: text, markdown, html, latex, rst, org, adoc, json, jsonl, csv or tsv to re-emit delimited records, nul for tab-separated records ending in NUL bytes, or sql for INSERT statements")
+	flag.StringVar(&opts.SQLTable, "table", "", "the `name` of the table into which -format sql inserts rows")
+	flag.BoolVar(&opts.SQLNulls, "sql-nulls", false, "whether -format sql inserts empty cells as NULL, rather than as empty strings")
+	flag.BoolVar(&widths, "widths", false, "whether to report the display width of each column to stderr after formatting")
+	flag.BoolVar(&check, "check", false, "whether to only check that every input row has as many fields as the first, reporting those that don't to stderr, rather than format the input")
+	flag.BoolVar(&verbose, "verbose", false, "whether to report the delimiter detected with -d auto to stderr")
+	flag.BoolVar(&printVersion, "version", false, "print the version of ftable and exit")
+	flag.StringVar(&config, "config", "", "read default flags from the config `file` (default: $XDG_CONFIG_HOME/ftable/config)")
+	flag.StringVar(&output, "o", "", "write output to `file` instead of stdout")
+	flag.Var(&flags, "flags", "any comma-separated combination of the flags: filter-html, strip-escape, align-right, discard-empty, tab-indent, debug (only align-right and discard-empty apply with -box)")
+
+	// Defaults are read from the config file, then $FTABLE_OPTS, and then the command line, each overriding the
+	// flags set by those before it.
+	var envArgs []string
+	if env := os.Getenv("FTABLE_OPTS"); env != "" {
+		args, err := splitArgs(env)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid FTABLE_OPTS: %v\n", err)
+			return exitError
+		}
+		envArgs = args
+	}
+
+	configPath, explicit := configFlag(os.Args[1:])
+	if !explicit {
+		configPath, explicit = configFlag(envArgs)
+	}
+	if !explicit {
+		configPath = defaultConfigPath()
+	}
+	if configPath != "" {
+		if err := loadConfig(configPath); err != nil && (explicit || !os.IsNotExist(err)) {
+			fmt.Fprintf(os.Stderr, "error reading config: %v\n", err)
+			return exitError
+		}
+	}
+
+	if envArgs != nil {
+		flag.CommandLine.Parse(envArgs)
+		if flag.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "invalid FTABLE_OPTS: unexpected argument %q; only flags are allowed\n", flag.Arg(0))
+			return exitError
+		}
+	}
+	flag.Parse()
+
+	if printVersion {
+		fmt.Printf("github.com/nilium/ftable %s (%s)\n", version, runtime.Version())
+		return exitOK
+	}
+
+	if n := utf8.RuneCountInString(padchar); n != 1 {
+		fmt.Fprintf(os.Stderr, "invalid padchar of length %d\n", n)
+		return exitError
+	}
+	opts.PadChar, _ = utf8.DecodeRuneInString(padchar)
+	if trim {
+		opts.TrimLeft, opts.TrimRight = true, true
+	}
+	opts.Flags = uint(flags)
+
+	if aligns != "" {
+		a, err := ftable.ParseAlignments(aligns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -align: %v\n", err)
+			return exitError
+		}
+		opts.Aligns = a
+	}
+
+	switch color {
+	case "auto", "always", "never":
+	default:
+		fmt.Fprintf(os.Stderr, "invalid -color %q\n", color)
+		return exitError
+	}
+
+	if headerColor != "" {
+		sgr, err := ftable.ParseColor(headerColor)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -headercolor: %v\n", err)
+			return exitError
+		}
+		opts.HeaderColor = sgr
+	}
+	if boldHeader {
+		if opts.HeaderColor == "" {
+			opts.HeaderColor = "1"
+		} else {
+			opts.HeaderColor = "1;" + opts.HeaderColor
+		}
+	}
+
+	if highlights != "" {
+		h, err := ftable.ParseHighlights(highlights)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -highlight: %v\n", err)
+			return exitError
+		}
+		opts.Highlights = h
+	}
+	if highlightColor != "" {
+		sgr, err := ftable.ParseColor(highlightColor)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -highlight-color: %v\n", err)
+			return exitError
+		}
+		opts.HighlightColor = sgr
+	}
+
+	if match != "" {
+		re, err := regexp.Compile(match)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -match regexp: %v\n", err)
+			return exitError
+		}
+		opts.Match = re
+	}
+	if matchColor != "" {
+		sgr, err := ftable.ParseColor(matchColor)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -match-color: %v\n", err)
+			return exitError
+		}
+		opts.MatchColor = sgr
+	}
+
+	if zebra {
+		pair := strings.Split(zebraColors, ",")
+		if len(pair) != 2 {
+			fmt.Fprintf(os.Stderr, "invalid -zebracolors %q: must be a pair of colors\n", zebraColors)
+			return exitError
+		}
+		for i, color := range pair {
+			if color == "" {
+				continue
+			}
+			sgr, err := ftable.ParseColor(color)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "invalid -zebracolors: %v\n", err)
+				return exitError
+			}
+			opts.Zebra[i] = sgr
+		}
+	}
+
+	if opts.SortNumeric && opts.SortNatural {
+		fmt.Fprintln(os.Stderr, "-numeric and -natural cannot both be set")
+		return exitError
+	}
+	if sortKeys != "" {
+		k, err := ftable.ParseSortKeys(sortKeys)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -sort: %v\n", err)
+			return exitError
+		}
+		opts.SortKeys = k
+	}
+
+	if aggs != "" {
+		a, err := ftable.ParseAggregates(aggs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -agg: %v\n", err)
+			return exitError
+		}
+		opts.Aggregates = a
+	}
+
+	if grouping {
+		if utf8.RuneCountInString(groupChar) != 1 {
+			fmt.Fprintf(os.Stderr, "invalid -grouping-char %q: must be a single character\n", groupChar)
+			return exitError
+		}
+		opts.Grouping, _ = utf8.DecodeRuneInString(groupChar)
+	}
+
+	if colWidths != "" {
+		cw, err := ftable.ParseColWidths(colWidths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -colwidth: %v\n", err)
+			return exitError
+		}
+		opts.ColWidths = cw
+	}
+
+	if cols != "" {
+		c, err := ftable.ParseColumns(cols)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -cols: %v\n", err)
+			return exitError
+		}
+		opts.Cols = c
+	}
+	if humanize != "" {
+		c, err := ftable.ParseColumns(humanize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -humanize: %v\n", err)
+			return exitError
+		}
+		opts.Humanize = c
+	}
+	if dehumanize != "" {
+		c, err := ftable.ParseColumns(dehumanize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -dehumanize: %v\n", err)
+			return exitError
+		}
+		opts.Dehumanize = c
+	}
+	if collapse != "" {
+		c, err := ftable.ParseColumns(collapse)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -collapse: %v\n", err)
+			return exitError
+		}
+		opts.Collapse = c
+	}
+	if checks != "" {
+		c, err := ftable.ParseColumns(checks)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -check-col: %v\n", err)
+			return exitError
+		}
+		opts.Checks = c
+	}
+	if exclude != "" {
+		c, err := ftable.ParseColumns(exclude)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -exclude: %v\n", err)
+			return exitError
+		}
+		opts.Exclude = c
+	}
+
+	opts.Style = ftable.Style(style)
+
+	switch {
+	case fixed == "":
+	case opts.CSV || opts.Delim != "" || delimRE != "" || nulFields || opts.From != "":
+		fmt.Fprintln(os.Stderr, "-fixed cannot be set with -csv, -d, -D, -z, or -from")
+		return exitError
+	case fixed == "auto":
+		opts.FixedAuto = true
+	default:
+		w, err := ftable.ParseFixedWidths(fixed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -fixed: %v\n", err)
+			return exitError
+		}
+		opts.Fixed = w
+	}
+
+	if opts.From != "" && (opts.CSV || opts.Delim != "" || delimRE != "" || nulFields) {
+		fmt.Fprintf(os.Stderr, "-from %s cannot be set with -csv, -d, -D, or -z\n", opts.From)
+		return exitError
+	}
+
+	switch {
+	case nulFields && nulRecords:
+		fmt.Fprintln(os.Stderr, "-z and -Z cannot both be set")
+		return exitError
+	case nulFields && (opts.Delim != "" || delimRE != ""):
+		fmt.Fprintln(os.Stderr, "-z cannot be set with -d or -D")
+		return exitError
+	case nulFields && opts.CSV:
+		fmt.Fprintln(os.Stderr, "-z cannot be set with -csv")
+		return exitError
+	case nulFields:
+		opts.Delim = "\x00"
+	case nulRecords && opts.RecordSep != "":
+		fmt.Fprintln(os.Stderr, "-Z cannot be set with -rs")
+		return exitError
+	case nulRecords:
+		opts.RecordSep = "\x00"
+	}
+
+	if opts.CSV && opts.Delim != "" && opts.Delim != ftable.DelimAuto {
+		if utf8.RuneCountInString(opts.Delim) != 1 {
+			fmt.Fprintf(os.Stderr, "invalid -csv delimiter %q: must be a single character\n", opts.Delim)
+			return exitError
+		}
+		opts.Comma, _ = utf8.DecodeRuneInString(opts.Delim)
+	}
+
+	if delimRE != "" {
+		re, err := regexp.Compile(delimRE)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -D regexp: %v\n", err)
+			return exitError
+		}
+		opts.DelimRE = re
+	}
+
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	if !set["ascii"] && !set["style"] {
+		ascii = !utf8Locale()
+	}
+	if !set["ellipsis"] && !utf8Locale() {
+		opts.Ellipsis = "..."
+	}
+	if ascii {
+		opts.Style = ftable.StyleASCII
+	}
+	if showControl {
+		opts.ShowControl = "picture"
+		if ascii || !utf8Locale() {
+			opts.ShowControl = "caret"
+		}
+	}
+
+	// Bad options are reported before -o truncates the output or any input is read.
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitError
+	}
+
+	dest := os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error opening output: %v\n", err)
+			return exitError
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "error closing output: %v\n", err)
+				code = exitError
+			}
+		}()
+		dest = f
+	}
+
+	if !set["width"] {
+		opts.Width = terminalWidth(dest)
+	}
+	if !colorEnabled(dest, color) {
+		opts.HeaderColor, opts.Zebra = "", [2]string{}
+		opts.Link = 0
+		opts.Highlights, opts.Match = nil, nil
+	}
+
+	t := ftable.New(ftable.WithOptions(opts))
+	switch {
+	case check:
+		return checkFields(flag.Args(), t)
+	case labels && flag.NArg() > 0:
+		return formatLabeled(dest, flag.Args(), opts, verbose, widths)
+	}
+	return format(dest, flag.Args(), t, verbose, widths)
+}
+
+// formatLabeled formats each of the named inputs as a separate table with opts, titled with its name, as format
+// does. Tables are separated by blank lines. It returns exitError if any input failed, or exitEmpty if every
+// input was empty and so drew no box.
+func formatLabeled(dest io.Writer, inputs []string, opts ftable.Options, verbose, widths bool) int {
+	code := exitEmpty
+	sep := &separator{w: dest}
+	for _, name := range inputs {
+		opts := opts
+		opts.Title = name
+		switch format(sep, []string{name}, ftable.New(ftable.WithOptions(opts)), verbose, widths) {
+		case exitError:
+			code = exitError
+		case exitOK:
+			if code == exitEmpty {
+				code = exitOK
+			}
+		}
+		sep.next = sep.wrote
+	}
+	return code
+}
+
+// separator is a writer that writes a line ending to w before the first write that follows setting next,
+// separating what was written before from what is written after.
+type separator struct {
+	w           io.Writer
+	next, wrote bool
+}
+
+func (s *separator) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if s.next {
+		if _, err := io.WriteString(s.w, "\n"); err != nil {
+			return 0, err
+		}
+		s.next = false
+	}
+	s.wrote = true
+	return s.w.Write(p)
+}
+
+// checkFields reads the named inputs (or stdin) into t and reports each row with a different number of fields
+// than the first to stderr, returning exitError if there are any such rows or any input could not be read.
+func checkFields(inputs []string, t *ftable.Table) int {
+	ok := eachInput(inputs, func(r io.Reader) error {
+		_, err := t.ReadFrom(r)
+		return err
+	})
+
+	counts := t.FieldCounts()
+	for n, count := range counts {
+		if count != counts[0] {
+			fmt.Fprintf(os.Stderr, "row %d has %d fields, but row 1 has %d\n", n+1, count, counts[0])
+			ok = false
+		}
+	}
+	return exitCode(ok)
+}
+
+// format reads the named inputs (or stdin) into t and writes its table to dest, returning an exit code:
+// exitError if any input could not be read or output could not be written, or exitEmpty if there was nothing to
+// draw a box around. If verbose is set, the delimiter detected for -d auto is reported to stderr, and if widths is
+// set, so are the widths of the table's columns.
+func format(dest io.Writer, inputs []string, t *ftable.Table, verbose, widths bool) int {
+	auto := t.Delim == ftable.DelimAuto
+	ok := eachInput(inputs, func(r io.Reader) error {
+		_, err := t.ReadFrom(r)
+		return err
+	})
+	if verbose && auto && t.Delim != ftable.DelimAuto {
+		fmt.Fprintf(os.Stderr, "detected delimiter %q\n", t.Delim)
+	}
+
+	// All output is buffered so that write errors are sticky and surface once, when flushed, whether or not
+	// Render saw them first.
+	out := bufio.NewWriter(dest)
+	err := t.Render(out)
+	if widths && err == nil {
+		reportWidths(t.Widths())
+	}
+	if ferr := out.Flush(); ferr != nil {
+		// A closed pipe means the reader has all the output it wants, so it's only an error for the exit
+		// status. Stdout never gets here, since the runtime exits on its SIGPIPE, but a FIFO named by -o
+		// does.
+		if !brokenPipe(ferr) {
+			fmt.Fprintf(os.Stderr, "error writing output: %v\n", ferr)
+		}
+		return exitError
+	}
+
+	switch {
+	case err == ftable.ErrEmpty && ok:
+		return exitEmpty
+	case err == ftable.ErrEmpty:
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitError
+	}
+	return exitCode(ok)
+}
+
+// reportWidths writes widths to stderr as a line of column widths, such as "col1=12 col2=4".
+func reportWidths(widths []int) {
+	cols := make([]string, len(widths))
+	for i, w := range widths {
+		cols[i] = fmt.Sprintf("col%d=%d", i+1, w)
+	}
+	fmt.Fprintln(os.Stderr, strings.Join(cols, " "))
+}
+
+// styleNames returns the names of the box styles known to ftable.
+func styleNames() []string {
+	var names []string
+	for _, style := range ftable.BoxStyles() {
+		names = append(names, string(style))
+	}
+	return names
+}
+
+// exitCode returns exitOK if ok is true and exitError otherwise.
+func exitCode(ok bool) int {
+	if ok {
+		return exitOK
+	}
+	return exitError
+}
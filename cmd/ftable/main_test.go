@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/nilium/ftable"
+)
+
+// TestMain runs the ftable command in place of the tests when the test binary is run by runFtable.
+func TestMain(m *testing.M) {
+	if os.Getenv("FTABLE_TEST_MAIN") == "1" {
+		os.Exit(run())
+	}
+	os.Exit(m.Run())
+}
+
+// runFtable runs the ftable command with args and env, reading in from stdin, and returns what it wrote to
+// stdout and stderr and its exit code. The environment is otherwise cleared of anything ftable reads, and no
+// config file is read unless one is named.
+func runFtable(t *testing.T, in string, env []string, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = []string{"FTABLE_TEST_MAIN=1", "XDG_CONFIG_HOME=" + t.TempDir(), "HOME=" + t.TempDir()}
+	cmd.Env = append(cmd.Env, env...)
+	cmd.Stdin = strings.NewReader(in)
+
+	var outb, errb bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &outb, &errb
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		code = exitErr.ExitCode()
+	case err != nil:
+		t.Fatal(err)
+	}
+	return outb.String(), errb.String(), code
+}
+
+func TestTabFlagsSet(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    uint
+		wantErr bool
+	}{
+		{in: "debug", want: tabwriter.Debug},
+		{in: "align-right,debug", want: tabwriter.AlignRight | tabwriter.Debug},
+		{in: "filter-html,strip-escape,tab-indent", want: tabwriter.FilterHTML | tabwriter.StripEscape | tabwriter.TabIndent},
+		{in: "discard-empty,discard-empty", want: tabwriter.DiscardEmptyColumns},
+		{in: "align-right,bogus", wantErr: true},
+		{in: "align-right,", wantErr: true},
+	}
+	for _, tt := range tests {
+		var f tabFlags
+		err := f.Set(tt.in)
+		switch {
+		case tt.wantErr && err == nil:
+			t.Errorf("Set(%q) = nil, want an error", tt.in)
+		case !tt.wantErr && err != nil:
+			t.Errorf("Set(%q) = %v, want nil", tt.in, err)
+		case !tt.wantErr && uint(f) != tt.want:
+			t.Errorf("Set(%q) = %#x, want %#x", tt.in, uint(f), tt.want)
+		}
+	}
+}
+
+// failWriter is an io.Writer whose writes all fail with err.
+type failWriter struct {
+	err error
+}
+
+func (w failWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestFormatWriteError(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "input")
+	if err := os.WriteFile(input, []byte("a\tb\n1\t2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		opts ftable.Options
+	}{
+		{"text", errors.New("disk full"), ftable.Options{}},
+		{"box", errors.New("disk full"), ftable.Options{Box: true}},
+		{"closed pipe", io.ErrClosedPipe, ftable.Options{Box: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := format(failWriter{tt.err}, []string{input}, ftable.New(ftable.WithOptions(tt.opts)), false, false)
+			if code != exitError {
+				t.Errorf("format() = %d, want %d", code, exitError)
+			}
+		})
+	}
+}
+
+func TestInvalidOptionsKeepOutput(t *testing.T) {
+	tests := [][]string{
+		{"-format", "yaml"},
+		{"-box", "-style", "wavy"},
+		{"-title", "t", "-title-pos", "left"},
+		{"-valign", "sideways"},
+	}
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "out")
+			if err := os.WriteFile(output, []byte("kept\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, stderr, code := runFtable(t, "a\tb\n", nil, append(args, "-o", output)...)
+			if code != exitError {
+				t.Errorf("exited with %d, want %d", code, exitError)
+			}
+			if !strings.Contains(stderr, "unrecognized") {
+				t.Errorf("stderr = %q, want an unrecognized option error", stderr)
+			}
+			if b, err := os.ReadFile(output); err != nil || string(b) != "kept\n" {
+				t.Errorf("output = %q, %v, want it untouched", b, err)
+			}
+		})
+	}
+}
+
+func TestRenderStringMatchesCLI(t *testing.T) {
+	const in = "name\tqty\napple\t3\nwatermelon\t12\n"
+	tests := []struct {
+		args []string
+		opts ftable.Options
+	}{
+		{nil, ftable.Options{Padding: 1}},
+		{[]string{"-box"}, ftable.Options{Box: true, Padding: 1}},
+		{[]string{"-box", "-header"}, ftable.Options{Box: true, Header: true, Padding: 1}},
+		{[]string{"-box", "-header", "-footer", "-rowlines"}, ftable.Options{Box: true, Header: true, Footer: true, RowLines: true, Padding: 1}},
+		{[]string{"-padding", "4", "-minwidth", "8"}, ftable.Options{Padding: 4, MinWidth: 8}},
+		{[]string{"-format", "markdown", "-header"}, ftable.Options{Format: "markdown", Header: true, Padding: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			want, err := ftable.RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, stderr, code := runFtable(t, in, nil, tt.args...)
+			if code != exitOK {
+				t.Fatalf("ftable %v exited with %d: %s", tt.args, code, stderr)
+			}
+			if got != want {
+				t.Errorf("ftable %v wrote:\n%s\nRenderString returned:\n%s", tt.args, got, want)
+			}
+		})
+	}
+}
+
+func TestGzipFile(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	io.WriteString(zw, "name,qty\napple,3\n")
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "fruit.csv.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"-csv", path}, {"-csv"}} {
+		in := ""
+		if len(args) == 1 {
+			in = buf.String()
+		}
+		stdout, stderr, code := runFtable(t, in, nil, args...)
+		if want := "name  qty\napple 3\n"; stdout != want || code != 0 {
+			t.Errorf("ftable %q = %q, %q, exit %d, want %q", args, stdout, stderr, code, want)
+		}
+	}
+}
+
+func TestCenterWidth(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    []string
+		args   []string
+		indent int
+	}{
+		{"flag", nil, []string{"-box", "-center", "-width", "29"}, 10},
+		{"flag over COLUMNS", []string{"COLUMNS=80"}, []string{"-box", "-center", "-width", "29"}, 10},
+		// Output to a pipe has no terminal width, so $COLUMNS is ignored.
+		{"not a terminal", []string{"COLUMNS=29"}, []string{"-box", "-center"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runFtable(t, "a\tb\n", tt.env, tt.args...)
+			if code != 0 {
+				t.Fatalf("exit %d: %s", code, stderr)
+			}
+			pad := strings.Repeat(" ", tt.indent)
+			if want := pad + "┌───┬───┐\n" + pad + "│ a │ b │\n" + pad + "└───┴───┘\n"; stdout != want {
+				t.Errorf("got:\n%s\nwant:\n%s", stdout, want)
+			}
+		})
+	}
+}
+
+// sgrEscape matches the SGR escape sequences with which ftable colors output.
+var sgrEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func TestBoldHeader(t *testing.T) {
+	const in = "name\tqty\napple\t3\n"
+	plain, _, _ := runFtable(t, in, nil, "-box", "-header")
+	tests := []struct {
+		name string
+		env  []string
+		args []string
+		bold bool
+	}{
+		{"always", nil, []string{"-color", "always"}, true},
+		{"with headercolor", nil, []string{"-color", "always", "-headercolor", "31"}, true},
+		{"never", nil, []string{"-color", "never"}, false},
+		{"not a terminal", nil, nil, false},
+		{"NO_COLOR", []string{"NO_COLOR=1"}, []string{"-color", "auto"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"-box", "-header", "-bold-header"}, tt.args...)
+			stdout, stderr, code := runFtable(t, in, tt.env, args...)
+			if code != 0 {
+				t.Fatalf("exit %d: %s", code, stderr)
+			}
+			if bold := strings.Contains(stdout, "\x1b[1"); bold != tt.bold {
+				t.Errorf("bold = %v, want %v:\n%s", bold, tt.bold, stdout)
+			}
+			if got := sgrEscape.ReplaceAllString(stdout, ""); got != plain {
+				t.Errorf("without its escapes, got:\n%s\nwant:\n%s", got, plain)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		args   []string
+		stderr string
+		code   int
+	}{
+		{"consistent", "a\tb\nc\td\n", nil, "", 0},
+		{"inconsistent", "a\tb\nc\nd\te\tf\ng\th\n", nil, "row 2 has 1 fields, but row 1 has 2\nrow 3 has 3 fields, but row 1 has 2\n", 1},
+		{"delimiter", "a,b\nc,d\n", []string{"-d", ","}, "", 0},
+		{"csv", "a,\"b,c\"\nd,\"e\nf\"\n", []string{"-csv"}, "", 0},
+		{"inconsistent csv", "a,b\n\"c,d\"\n", []string{"-csv"}, "row 2 has 1 fields, but row 1 has 2\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runFtable(t, tt.in, nil, append([]string{"-check"}, tt.args...)...)
+			if stdout != "" {
+				t.Errorf("-check wrote a table: %q", stdout)
+			}
+			if stderr != tt.stderr || code != tt.code {
+				t.Errorf("got %q, exit %d, want %q, exit %d", stderr, code, tt.stderr, tt.code)
+			}
+		})
+	}
+}
+
+func TestLabels(t *testing.T) {
+	dir := t.TempDir()
+	a, b, empty := filepath.Join(dir, "a.tsv"), filepath.Join(dir, "b.tsv"), filepath.Join(dir, "empty.tsv")
+	for path, contents := range map[string]string{a: "x\t1\n", b: "yy\t22\n", empty: ""} {
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	box := func(title, cells string) string {
+		out, _, _ := runFtable(t, cells, nil, "-box", "-title", title)
+		if !strings.Contains(out, title) {
+			t.Fatalf("-title %q is not drawn:\n%s", title, out)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		inputs []string
+		want   string
+		code   int
+	}{
+		{"two files", []string{a, b}, box(a, "x\t1\n") + "\n" + box(b, "yy\t22\n"), 0},
+		{"empty file skipped", []string{a, empty, b}, box(a, "x\t1\n") + "\n" + box(b, "yy\t22\n"), 0},
+		{"only empty", []string{empty}, "", 3},
+		{"missing file", []string{a, filepath.Join(dir, "missing")}, box(a, "x\t1\n"), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"-box", "-labels", "-title", "ignored"}, tt.inputs...)
+			stdout, stderr, code := runFtable(t, "", nil, args...)
+			if stdout != tt.want || code != tt.code {
+				t.Errorf("got exit %d, %q:\n%s\nwant exit %d:\n%s", code, stderr, stdout, tt.code, tt.want)
+			}
+		})
+	}
+}
+
+func TestNUL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		args []string
+		want string
+	}{
+		{"records round trip", "a b\tc\nd\x00e\tf\x00", []string{"-Z", "-format", "nul"}, "a b\tc\nd\x00e\tf\x00"},
+		{"records to box", "name\tnote\x00x\tone\ntwo\x00", []string{"-Z", "-box"},
+			"┌──────┬──────┐\n│ name │ note │\n│ x    │ one  │\n│      │ two  │\n└──────┴──────┘\n"},
+		{"fields to tsv", "a b\x00c\td\ne\x00f\n", []string{"-z", "-format", "tsv"}, "a b\tc       d\ne\tf\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runFtable(t, tt.in, nil, tt.args...)
+			if stdout != tt.want || code != 0 {
+				t.Errorf("got exit %d, %q: %q, want %q", code, stderr, stdout, tt.want)
+			}
+		})
+	}
+
+	if _, _, code := runFtable(t, "", nil, "-z", "-Z"); code != 1 {
+		t.Errorf("-z with -Z exited %d, want 1", code)
+	}
+}
+
+func TestExclude(t *testing.T) {
+	const in = "a\tb\tc\td\n1\t2\t3\t4\n"
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-exclude", "2,3"}, "a d\n1 4\n"},
+		{[]string{"-exclude", "2-3"}, "a d\n1 4\n"},
+		{[]string{"-exclude", "4"}, "a b c\n1 2 3\n"},
+		{[]string{"-exclude", "4,9"}, "a b c\n1 2 3\n"},
+		{[]string{"-cols", "4,1,2", "-exclude", "2"}, "d a\n4 1\n"},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runFtable(t, in, nil, tt.args...)
+		if stdout != tt.want || code != 0 {
+			t.Errorf("ftable %q = exit %d, %q, %q, want %q", tt.args, code, stderr, stdout, tt.want)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !plan9
+
+package main
+
+import (
+	"errors"
+	"syscall"
+)
+
+// brokenPipe reports whether err is a write to a pipe with no reader.
+func brokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE)
+}
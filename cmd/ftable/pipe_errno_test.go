@@ -0,0 +1,30 @@
+//go:build !plan9
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestBrokenPipe(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{syscall.EPIPE, true},
+		{&os.PathError{Op: "write", Path: "out", Err: syscall.EPIPE}, true},
+		{fmt.Errorf("flush: %w", syscall.EPIPE), true},
+		{io.ErrClosedPipe, false},
+		{errors.New("disk full"), false},
+	}
+	for _, tt := range tests {
+		if got := brokenPipe(tt.err); got != tt.want {
+			t.Errorf("brokenPipe(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
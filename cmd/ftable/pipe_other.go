@@ -0,0 +1,8 @@
+//go:build plan9
+
+package main
+
+// brokenPipe returns false, since writes to a closed pipe are not reported as EPIPE on this platform.
+func brokenPipe(err error) bool {
+	return false
+}
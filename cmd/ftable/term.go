@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"strconv"
+)
+
+// isTerminal reports whether f is a terminal. Other character devices, such as /dev/null, are not terminals.
+func isTerminal(f *os.File) bool {
+	return ioctlTerminal(f)
+}
+
+// terminalWidth returns the width, in columns, of the terminal f, falling back to $COLUMNS if it cannot be
+// queried. It returns 0 if f is not a terminal or its width is unknown.
+func terminalWidth(f *os.File) int {
+	if !isTerminal(f) {
+		return 0
+	}
+	if n := ioctlWidth(f); n > 0 {
+		return n
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return 0
+}
+
+// colorEnabled reports whether output to f may be colored, given the -color mode: always if mode is "always",
+// never if it is "never", and otherwise, for "auto", only if f is a terminal and $NO_COLOR is unset or empty
+// (see https://no-color.org). All coloring is gated on it.
+func colorEnabled(f *os.File, mode string) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	return isTerminal(f) && os.Getenv("NO_COLOR") == ""
+}
@@ -0,0 +1,28 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// ioctlWidth returns the width of the terminal f as reported by the TIOCGWINSZ ioctl, or 0 if it fails.
+func ioctlWidth(f *os.File) int {
+	var ws struct {
+		row, col, xpixel, ypixel uint16
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0
+	}
+	return int(ws.col)
+}
+
+// ioctlTerminal reports whether f is a terminal by querying its terminal attributes, which only terminals have.
+func ioctlTerminal(f *os.File) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(ioctlGetTermios), uintptr(unsafe.Pointer(&termios)))
+	return errno == 0
+}
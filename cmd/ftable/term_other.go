@@ -0,0 +1,16 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package main
+
+import "os"
+
+// ioctlWidth returns 0, since terminal sizes cannot be queried on this platform.
+func ioctlWidth(f *os.File) int {
+	return 0
+}
+
+// ioctlTerminal reports whether f is a character device, the closest this platform comes to a terminal check.
+func ioctlTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
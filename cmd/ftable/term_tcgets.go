@@ -0,0 +1,8 @@
+//go:build linux
+
+package main
+
+import "syscall"
+
+// ioctlGetTermios is the ioctl request that reads a terminal's attributes.
+const ioctlGetTermios = syscall.TCGETS
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestColorEnabled(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	tests := []struct {
+		mode, noColor string
+		want          bool
+	}{
+		{"always", "", true},
+		{"always", "1", true},
+		{"never", "", false},
+		{"auto", "", false},
+		{"auto", "1", false},
+	}
+	for _, tt := range tests {
+		t.Setenv("NO_COLOR", tt.noColor)
+		if got := colorEnabled(f, tt.mode); got != tt.want {
+			t.Errorf("colorEnabled(file, %q) with NO_COLOR=%q = %v, want %v", tt.mode, tt.noColor, got, tt.want)
+		}
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+
+	if isTerminal(f) {
+		t.Errorf("isTerminal(%s) = true, want false", os.DevNull)
+	}
+	if colorEnabled(f, "auto") {
+		t.Errorf("colorEnabled(%s, auto) = true, want false", os.DevNull)
+	}
+	if n := terminalWidth(f); n != 0 {
+		t.Errorf("terminalWidth(%s) = %d, want 0", os.DevNull, n)
+	}
+}
+
+func TestColorModes(t *testing.T) {
+	const in = "a\tb\n1\t2\n"
+	tests := []struct {
+		mode    string
+		env     []string
+		colored bool
+	}{
+		{"always", nil, true},
+		{"always", []string{"NO_COLOR=1"}, true},
+		{"never", nil, false},
+		{"auto", nil, false},
+		{"auto", []string{"NO_COLOR=1"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode+" "+strings.Join(tt.env, " "), func(t *testing.T) {
+			out, stderr, code := runFtable(t, in, tt.env, "-box", "-header", "-headercolor", "red", "-zebra", "-color", tt.mode)
+			if code != exitOK {
+				t.Fatalf("exited with %d: %s", code, stderr)
+			}
+			if colored := strings.Contains(out, "\x1b["); colored != tt.colored {
+				t.Errorf("-color %s colored output = %v, want %v:\n%s", tt.mode, colored, tt.colored, out)
+			}
+		})
+	}
+
+	if _, _, code := runFtable(t, in, nil, "-color", "sometimes"); code != exitError {
+		t.Errorf("-color sometimes exited with %d, want %d", code, exitError)
+	}
+}
@@ -0,0 +1,107 @@
+package ftable
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ColWidth clamps the display width of a column of text and box output.
+type ColWidth struct {
+	// Col is the 0-based index of the column to clamp, counting any row number column added by Number.
+	Col int
+	// Min and Max, if greater than zero, are the least and greatest width of the column. Narrower columns are
+	// padded to Min; wider cells are truncated to Max or, in a box, wrapped to it.
+	Min, Max int
+}
+
+// ParseColWidths parses a comma-separated list of column widths, each a 1-based column number and a range of
+// widths, separated by a colon, such as "2:5-20". Either end of a range may be omitted, as in "2:5-" or "2:-20",
+// and a single width, as in "2:10", sets both.
+func ParseColWidths(v string) ([]ColWidth, error) {
+	var widths []ColWidth
+	for _, field := range strings.Split(v, ",") {
+		col, rng, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid column width %q: must be of the form column:min-max", field)
+		}
+
+		n, err := strconv.Atoi(col)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid column %q", col)
+		}
+
+		lo, hi, ok := strings.Cut(rng, "-")
+		if !ok {
+			hi = lo
+		}
+		cw := ColWidth{Col: n - 1}
+		if cw.Min, err = parseWidth(lo); err != nil {
+			return nil, fmt.Errorf("invalid minimum width %q", lo)
+		}
+		if cw.Max, err = parseWidth(hi); err != nil {
+			return nil, fmt.Errorf("invalid maximum width %q", hi)
+		}
+		if cw.Max > 0 && cw.Min > cw.Max {
+			return nil, fmt.Errorf("invalid width range %q", rng)
+		}
+		widths = append(widths, cw)
+	}
+	return widths, nil
+}
+
+// parseWidth parses a width of a ColWidth range, which is zero if s is empty.
+func parseWidth(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid width %q", s)
+	}
+	return n, nil
+}
+
+// truncateColumns truncates the cells of rows, line by line, to the Max widths of the columns of cws.
+func truncateColumns(rows [][]string, cws []ColWidth, ellipsis string) {
+	for _, cw := range cws {
+		if cw.Max <= 0 {
+			continue
+		}
+		for _, row := range rows {
+			if cw.Col >= len(row) {
+				continue
+			}
+			lines := cellLines(row[cw.Col])
+			for h, line := range lines {
+				lines[h] = truncate(line, cw.Max, ellipsis)
+			}
+			row[cw.Col] = strings.Join(lines, lineBreak)
+		}
+	}
+}
+
+// padColumns pads the cells of rows to the Min widths of the columns of cws, per aligns, or to the left if
+// aligns is nil. As with alignRows, left-aligned cells at the end of a row are not padded, and the cells of column
+// indentCol are aligned after their leading whitespace.
+func padColumns(rows [][]string, cws []ColWidth, aligns []Alignment, pad rune, indentCol int) {
+	for _, cw := range cws {
+		if cw.Min <= 0 {
+			continue
+		}
+		align := AlignLeft
+		if cw.Col < len(aligns) {
+			align = aligns[cw.Col]
+		}
+		for _, row := range rows {
+			if cw.Col >= len(row) || (align == AlignLeft && cw.Col == len(row)-1) {
+				continue
+			}
+			if cw.Col == indentCol {
+				row[cw.Col] = alignIndented(row[cw.Col], cw.Min, align, pad)
+			} else {
+				row[cw.Col] = alignCell(row[cw.Col], cw.Min, align, pad)
+			}
+		}
+	}
+}
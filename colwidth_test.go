@@ -0,0 +1,108 @@
+package ftable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseColWidths(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []ColWidth
+		wantErr bool
+	}{
+		{"2:5-20", []ColWidth{{Col: 1, Min: 5, Max: 20}}, false},
+		{"1:5-", []ColWidth{{Col: 0, Min: 5}}, false},
+		{"1:-20", []ColWidth{{Col: 0, Max: 20}}, false},
+		{"3:10", []ColWidth{{Col: 2, Min: 10, Max: 10}}, false},
+		{"1:5-5,2:-8", []ColWidth{{Col: 0, Min: 5, Max: 5}, {Col: 1, Max: 8}}, false},
+		{"2", nil, true},
+		{"0:5-20", nil, true},
+		{"x:5-20", nil, true},
+		{"2:a-20", nil, true},
+		{"2:5-b", nil, true},
+		{"2:-1-5", nil, true},
+		{"2:20-5", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseColWidths(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseColWidths(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseColWidths(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRenderColWidths(t *testing.T) {
+	in := "id\tdescription\tqty\n1\ta very long description of the item\t3\n22\tshort\t10\n"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			"max", Options{Box: true, ColWidths: []ColWidth{{Col: 1, Max: 10}}},
+			"┌────┬────────────┬─────┐\n" +
+				"│ id │ descriptio │ qty │\n" +
+				"│    │ n          │     │\n" +
+				"│ 1  │ a very     │ 3   │\n" +
+				"│    │ long       │     │\n" +
+				"│    │ descriptio │     │\n" +
+				"│    │ n of the   │     │\n" +
+				"│    │ item       │     │\n" +
+				"│ 22 │ short      │ 10  │\n" +
+				"└────┴────────────┴─────┘\n",
+		},
+		{
+			"min", Options{Box: true, ColWidths: []ColWidth{{Col: 0, Min: 6}}},
+			"┌────────┬─────────────────────────────────────┬─────┐\n" +
+				"│ id     │ description                         │ qty │\n" +
+				"│ 1      │ a very long description of the item │ 3   │\n" +
+				"│ 22     │ short                               │ 10  │\n" +
+				"└────────┴─────────────────────────────────────┴─────┘\n",
+		},
+		{
+			"min and max", Options{Box: true, ColWidths: []ColWidth{{Col: 0, Min: 4}, {Col: 1, Min: 8, Max: 12}}},
+			"┌──────┬──────────────┬─────┐\n" +
+				"│ id   │ description  │ qty │\n" +
+				"│ 1    │ a very long  │ 3   │\n" +
+				"│      │ description  │     │\n" +
+				"│      │ of the item  │     │\n" +
+				"│ 22   │ short        │ 10  │\n" +
+				"└──────┴──────────────┴─────┘\n",
+		},
+		{
+			"wrapped", Options{Box: true, Width: 30, ColWidths: []ColWidth{{Col: 1, Max: 12}}},
+			"┌────┬──────────────┬─────┐\n" +
+				"│ id │ description  │ qty │\n" +
+				"│ 1  │ a very long  │ 3   │\n" +
+				"│    │ description  │     │\n" +
+				"│    │ of the item  │     │\n" +
+				"│ 22 │ short        │ 10  │\n" +
+				"└────┴──────────────┴─────┘\n",
+		},
+		{
+			"text", Options{Padding: 1, ColWidths: []ColWidth{{Col: 0, Min: 4}, {Col: 1, Max: 10}}},
+			"id   descripti… qty\n" +
+				"1    a very lo… 3\n" +
+				"22   short      10\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if tt.opts.Box {
+				checkAligned(t, got)
+			}
+		})
+	}
+}
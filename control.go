@@ -0,0 +1,50 @@
+package ftable
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// showControl returns s with its ASCII control characters, other than line breaks, replaced with printable forms:
+// caret notation, such as "^G", or, if pictures is set, the Unicode control pictures, such as "␇". The SGR and
+// OSC 8 escape sequences of colors and hyperlinks are kept, but the escape character of any other sequence is
+// replaced, so that it cannot move the cursor or otherwise disturb the terminal.
+func showControl(s string, pictures bool) string {
+	if strings.IndexFunc(s, isControl) < 0 {
+		return s
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 && keptEscape(s[i:i+n]) {
+			sb.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case !isControl(r):
+			sb.WriteRune(r)
+		case pictures && r == 0x7f:
+			sb.WriteRune('␡')
+		case pictures:
+			sb.WriteRune(0x2400 + r)
+		default:
+			sb.WriteByte('^')
+			sb.WriteByte(byte(r) ^ 0x40)
+		}
+	}
+	return sb.String()
+}
+
+// isControl reports whether r is an ASCII control character replaced by showControl.
+func isControl(r rune) bool {
+	return (r < 0x20 || r == 0x7f) && string(r) != lineBreak
+}
+
+// keptEscape reports whether esc, an escape sequence, is an SGR sequence or one delimiting a hyperlink.
+func keptEscape(esc string) bool {
+	return strings.HasPrefix(esc, "\x1b[") && strings.HasSuffix(esc, "m") || strings.HasPrefix(esc, "\x1b]8;")
+}
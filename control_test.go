@@ -0,0 +1,89 @@
+package ftable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShowControl(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		caret    string
+		pictures string
+	}{
+		{"plain", "plain text", "plain text", "plain text"},
+		{"carriage return", "50%\rdone", "50%^Mdone", "50%␍done"},
+		{"bell", "ding\x07", "ding^G", "ding␇"},
+		{"nul", "a\x00b", "a^@b", "a␀b"},
+		{"delete", "a\x7fb", "a^?b", "a␡b"},
+		{"cursor movement", "\x1b[2Jgone", "^[[2Jgone", "␛[2Jgone"},
+		{"bare escape", "a\x1bb", "a^[b", "a␛b"},
+		{"color kept", "\x1b[31mred\x1b[0m\r", "\x1b[31mred\x1b[0m^M", "\x1b[31mred\x1b[0m␍"},
+		{"hyperlink kept", "\x1b]8;;http://x\x1b\\x\x1b]8;;\x1b\\\x07", "\x1b]8;;http://x\x1b\\x\x1b]8;;\x1b\\^G", "\x1b]8;;http://x\x1b\\x\x1b]8;;\x1b\\␇"},
+		{"title kept out", "\x1b]0;title\x07x", "^[]0;title^Gx", "␛]0;title␇x"},
+		{"line breaks kept", "a" + lineBreak + "b\x01", "a" + lineBreak + "b^A", "a" + lineBreak + "b␁"},
+		{"multibyte", "漢\x07字", "漢^G字", "漢␇字"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := showControl(tt.s, false); got != tt.caret {
+				t.Errorf("showControl(%q, false) = %q, want %q", tt.s, got, tt.caret)
+			}
+			if got := showControl(tt.s, true); got != tt.pictures {
+				t.Errorf("showControl(%q, true) = %q, want %q", tt.s, got, tt.pictures)
+			}
+		})
+	}
+}
+
+func TestRenderShowControl(t *testing.T) {
+	// Input lines may end in a lone carriage return, so one reaches a cell only as a line break; a backspace
+	// stands in for it.
+	const in = "name\tstatus\nbuild\t50%\bdone\nbell\tding\x07\nclear\t\x1b[2Jx\n"
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{
+			"caret",
+			"┌───────┬───────────┐\n" +
+				"│ name  │ status    │\n" +
+				"│ build │ 50%^Hdone │\n" +
+				"│ bell  │ ding^G    │\n" +
+				"│ clear │ ^[[2Jx    │\n" +
+				"└───────┴───────────┘\n",
+		},
+		{
+			"picture",
+			"┌───────┬──────────┐\n" +
+				"│ name  │ status   │\n" +
+				"│ build │ 50%␈done │\n" +
+				"│ bell  │ ding␇    │\n" +
+				"│ clear │ ␛[2Jx    │\n" +
+				"└───────┴──────────┘\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			got, err := RenderString(in, Options{Box: true, ShowControl: tt.mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+			checkAligned(t, got)
+			if strings.ContainsAny(got, "\b\x07\x1b") {
+				t.Errorf("output holds control characters: %q", got)
+			}
+		})
+	}
+
+	if got := renderTSV(t, "a\rb\r\n", Options{ShowControl: "caret"}); got != "a\nb\n" {
+		t.Errorf("carriage returns: got %q, want them read as line endings", got)
+	}
+	if _, err := RenderString(in, Options{ShowControl: "hex"}); err == nil {
+		t.Error("no error for an unrecognized ShowControl")
+	}
+}
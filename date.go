@@ -0,0 +1,73 @@
+package ftable
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateFormat reformats the dates in a column with a time layout.
+type DateFormat struct {
+	// Col is the 0-based index of the column of dates.
+	Col int
+	// Layout is the time layout, as used by time.Format, with which dates are rewritten, such as "2006-01-02".
+	Layout string
+}
+
+// ParseDateFormat parses a date format: a 1-based column number and a time layout, separated by a colon, such as
+// "2:2006-01-02".
+func ParseDateFormat(v string) (DateFormat, error) {
+	col, layout, ok := strings.Cut(v, ":")
+	if !ok || layout == "" {
+		return DateFormat{}, fmt.Errorf("invalid date format %q: must be of the form column:layout", v)
+	}
+
+	n, err := strconv.Atoi(col)
+	if err != nil || n < 1 {
+		return DateFormat{}, fmt.Errorf("invalid column %q", col)
+	}
+	return DateFormat{Col: n - 1, Layout: layout}, nil
+}
+
+// dateLayouts are the layouts in which dates are parsed, in order, after any DateInput.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"01/02/2006 15:04:05",
+	"01/02/2006",
+	"02-Jan-2006",
+	"2 Jan 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.RubyDate,
+	time.UnixDate,
+	time.ANSIC,
+}
+
+// formatDate returns s, parsed as a date in the layout input or, if it is empty or does not match, any of
+// dateLayouts, rewritten with layout. If s is not a date in any of them, it is returned unchanged.
+func formatDate(s, layout, input string) string {
+	layouts := dateLayouts
+	if input != "" {
+		layouts = append([]string{input}, layouts...)
+	}
+	date := strings.TrimSpace(s)
+	for _, in := range layouts {
+		if t, err := time.Parse(in, date); err == nil {
+			return t.Format(layout)
+		}
+	}
+	return s
+}
@@ -0,0 +1,68 @@
+package ftable
+
+import "testing"
+
+func TestParseDateFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    DateFormat
+		wantErr bool
+	}{
+		{"2:2006-01-02", DateFormat{Col: 1, Layout: "2006-01-02"}, false},
+		{"1:15:04", DateFormat{Col: 0, Layout: "15:04"}, false},
+		{"2", DateFormat{}, true},
+		{"2:", DateFormat{}, true},
+		{"0:2006", DateFormat{}, true},
+		{"x:2006", DateFormat{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDateFormat(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDateFormat(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDateFormat(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	tests := []struct {
+		s, layout, input string
+		want             string
+	}{
+		{"2024-03-05", "2006-01-02", "", "2024-03-05"},
+		{"2024-03-05T14:30:00Z", "2006-01-02", "", "2024-03-05"},
+		{"2024-03-05T14:30:00.123+02:00", "2006-01-02 15:04", "", "2024-03-05 14:30"},
+		{"2024-03-05 14:30:00", "Jan 2 15:04", "", "Mar 5 14:30"},
+		{"2024/03/05", "2006-01-02", "", "2024-03-05"},
+		{"03/05/2024", "2006-01-02", "", "2024-03-05"},
+		{"05-Mar-2024", "2006-01-02", "", "2024-03-05"},
+		{"5 Mar 2024", "2006-01-02", "", "2024-03-05"},
+		{"March 5, 2024", "2006-01-02", "", "2024-03-05"},
+		{"Tue, 05 Mar 2024 14:30:00 +0000", "2006-01-02", "", "2024-03-05"},
+		{"Tue Mar  5 14:30:00 2024", "2006-01-02", "", "2024-03-05"},
+		{" 2024-03-05 ", "02.01.2006", "", "05.03.2024"},
+		{"05.03.2024", "2006-01-02", "02.01.2006", "2024-03-05"},
+		{"03/05/2024", "2006-01-02", "02/01/2006", "2024-05-03"},
+		{"2024-03-05", "2006-01-02", "02.01.2006", "2024-03-05"},
+		{"not a date", "2006-01-02", "", "not a date"},
+		{"2024-13-05", "2006-01-02", "", "2024-13-05"},
+		{"", "2006-01-02", "", ""},
+	}
+	for _, tt := range tests {
+		if got := formatDate(tt.s, tt.layout, tt.input); got != tt.want {
+			t.Errorf("formatDate(%q, %q, %q) = %q, want %q", tt.s, tt.layout, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRenderDates(t *testing.T) {
+	const in = "event\twhen\nstart\t2024-03-05T09:00:00Z\nlunch\tMar 5, 2024\nend\t05/03/2024 17:45:00\nlater\tsoon\n"
+	got := renderTSV(t, in, Options{Header: true, Dates: []DateFormat{{Col: 1, Layout: "2006-01-02"}}})
+	want := "event\twhen\nstart\t2024-03-05\nlunch\t2024-03-05\nend\t2024-05-03\nlater\tsoon\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
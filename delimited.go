@@ -0,0 +1,33 @@
+package ftable
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// writeDelimited writes rows to w as CSV records separated by comma, such as ',' for CSV or '\t' for TSV. Cells
+// are quoted as needed to hold the separator, quotes, or line breaks, which are written as newlines.
+func writeDelimited(w io.Writer, rows [][]string, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = strings.Replace(cell, lineBreak, "\n", -1)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeNUL writes rows to w as records each ending in a NUL byte, with their cells separated by tabs and the lines
+// within them by newlines, as read back with a RecordSep of "\x00".
+func writeNUL(w io.Writer, rows [][]string) {
+	for _, row := range rows {
+		io.WriteString(w, strings.Replace(strings.Join(row, "\t"), lineBreak, "\n", -1)+"\x00")
+	}
+}
@@ -0,0 +1,118 @@
+package ftable
+
+import (
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteDelimited(t *testing.T) {
+	rows := [][]string{{"a", "b,c"}, {`say "hi"`, "x" + lineBreak + "y"}, {"tab\there", ""}}
+	tests := []struct {
+		name  string
+		comma rune
+		want  string
+	}{
+		{"csv", ',', "a,\"b,c\"\n\"say \"\"hi\"\"\",\"x\ny\"\ntab\there,\n"},
+		{"tsv", '\t', "a\tb,c\n\"say \"\"hi\"\"\"\t\"x\ny\"\n\"tab\there\"\t\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := writeDelimited(&sb, rows, tt.comma); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"plain", "name,qty\napple,3\npear,7\n"},
+		{"separators", "name,note\napple,\"red, green\"\npear,\",\"\n"},
+		{"quotes", "name,note\napple,\"the \"\"best\"\"\"\npear,\"\"\"\"\n"},
+		{"newlines", "name,note\napple,\"line one\nline two\"\npear,\"\n\"\n"},
+		{"empty cells", "a,,c\n,,\nd,e,\n"},
+		{"spaces", "a,\" b \"\n\"c \",d\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := csv.NewReader(strings.NewReader(tt.in)).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := RenderString(tt.in, Options{CSV: true, Format: "csv"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+			if err != nil {
+				t.Fatalf("output %q is not CSV: %v", out, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got records %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRenderCSVTransformed(t *testing.T) {
+	in := "name,qty,note\npear,7,\"x, y\"\napple,3,\"say \"\"hi\"\"\"\n"
+	got, err := RenderString(in, Options{CSV: true, Format: "csv", Header: true, SortCol: 1, Cols: []int{2, 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "note,name\n\"say \"\"hi\"\"\",apple\n\"x, y\",pear\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteNUL(t *testing.T) {
+	var sb strings.Builder
+	writeNUL(&sb, [][]string{{"a", "b c"}, {"line one" + lineBreak + "line two"}, {}})
+	if got, want := sb.String(), "a\tb c\x00line one\nline two\x00\x00"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNULRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"plain", "a\tb\x00c\td\x00"},
+		{"spaces", "file name.txt\t12 kB\x00other file\t3 B\x00"},
+		{"newlines", "notes.txt\tline one\nline two\x00x\t\n\x00"},
+		{"carriage returns", "a\tb\r\nc\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(tt.in, Options{RecordSep: "\x00", Format: "nul"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := strings.Replace(tt.in, "\r\n", "\n", -1)
+			if got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+
+	// NUL-separated fields are read back from tab-separated NUL records.
+	got, err := RenderString("a b\x00c\nd\x00e\nf\x00g\n", Options{Delim: "\x00", Format: "nul"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a b\tc\x00d\te\x00f\tg\x00"; got != want {
+		t.Errorf("fields: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,136 @@
+package ftable
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Filter is a condition on a column that a data row must meet to be kept.
+type Filter struct {
+	// Col is the 0-based index of the column to test.
+	Col int
+	// Op names the comparison of the column's cell with Value: "~" if Regexp matches it, "=" if it is equal to
+	// Value, or one of ">", "<", ">=", or "<=" if it is a number comparing so with Value, which must also be a
+	// number.
+	Op    string
+	Value string
+	// Regexp is the expression matched by the "~" comparison.
+	Regexp *regexp.Regexp
+}
+
+// filterOps are the comparisons of a Filter, with those that are prefixes of others last.
+var filterOps = []string{">=", "<=", "~", "=", ">", "<"}
+
+// ParseFilter parses a filter: a 1-based column number, a comparison, and the value to compare with, such as
+// "2~^foo" or "3>=10".
+func ParseFilter(v string) (Filter, error) {
+	i := strings.IndexFunc(v, func(r rune) bool { return r < '0' || r > '9' })
+	if i < 0 {
+		return Filter{}, fmt.Errorf("invalid filter %q: must be of the form column, comparison, value", v)
+	}
+
+	n, err := strconv.Atoi(v[:i])
+	if err != nil || n < 1 {
+		return Filter{}, fmt.Errorf("invalid column %q", v[:i])
+	}
+
+	f := Filter{Col: n - 1}
+	for _, op := range filterOps {
+		if strings.HasPrefix(v[i:], op) {
+			f.Op, f.Value = op, v[i+len(op):]
+			break
+		}
+	}
+
+	switch f.Op {
+	case "":
+		return Filter{}, fmt.Errorf("unrecognized comparison in filter %q", v)
+	case "~":
+		if f.Regexp, err = regexp.Compile(f.Value); err != nil {
+			return Filter{}, err
+		}
+	case ">", "<", ">=", "<=":
+		if _, err := strconv.ParseFloat(f.Value, 64); err != nil {
+			return Filter{}, fmt.Errorf("invalid filter %q: %s requires a number", v, f.Op)
+		}
+	}
+	return f, nil
+}
+
+// match reports whether row meets the condition of f. A row without f's column is tested as though its cell
+// were empty. If fold is set, "=" compares cells with Value without regard to case.
+func (f *Filter) match(row []string, fold bool) bool {
+	c := cell(row, f.Col)
+	switch f.Op {
+	case "~":
+		return f.Regexp.MatchString(c)
+	case "=":
+		if fold {
+			return strings.EqualFold(c, f.Value)
+		}
+		return c == f.Value
+	}
+
+	x, err := strconv.ParseFloat(c, 64)
+	if err != nil {
+		return false
+	}
+	y, err := strconv.ParseFloat(f.Value, 64)
+	if err != nil {
+		return false
+	}
+	switch f.Op {
+	case ">":
+		return x > y
+	case "<":
+		return x < y
+	case ">=":
+		return x >= y
+	case "<=":
+		return x <= y
+	}
+	return false
+}
+
+// validate returns an error if f cannot be tested.
+func (f *Filter) validate() error {
+	switch f.Op {
+	case "~":
+		if f.Regexp == nil {
+			return fmt.Errorf("ftable: filter on column %d has no regexp", f.Col+1)
+		}
+	case "=", ">", "<", ">=", "<=":
+	default:
+		return fmt.Errorf("ftable: unrecognized filter comparison %q", f.Op)
+	}
+	return nil
+}
+
+// filterRows returns the rows that meet all of the conditions of filters. If fold is set, cells are compared and
+// matched without regard to case.
+func filterRows(rows [][]string, filters []Filter, fold bool) [][]string {
+	if fold {
+		folded := make([]Filter, len(filters))
+		for i, f := range filters {
+			if f.Op == "~" {
+				f.Regexp = regexp.MustCompile("(?i)" + f.Regexp.String())
+			}
+			folded[i] = f
+		}
+		filters = folded
+	}
+
+	var kept [][]string
+rows:
+	for _, row := range rows {
+		for i := range filters {
+			if !filters[i].match(row, fold) {
+				continue rows
+			}
+		}
+		kept = append(kept, row)
+	}
+	return kept
+}
@@ -0,0 +1,141 @@
+package ftable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		in      string
+		col     int
+		op      string
+		value   string
+		wantErr bool
+	}{
+		{in: "2~^foo", col: 1, op: "~", value: "^foo"},
+		{in: "1=bar", col: 0, op: "=", value: "bar"},
+		{in: "3>=10", col: 2, op: ">=", value: "10"},
+		{in: "3<=-1.5", col: 2, op: "<=", value: "-1.5"},
+		{in: "4>0", col: 3, op: ">", value: "0"},
+		{in: "4<1e3", col: 3, op: "<", value: "1e3"},
+		{in: "1=", col: 0, op: "=", value: ""},
+		{in: "1==x", col: 0, op: "=", value: "=x"},
+		{in: "2", wantErr: true},
+		{in: "0=x", wantErr: true},
+		{in: "~x", wantErr: true},
+		{in: "2!x", wantErr: true},
+		{in: "2~(", wantErr: true},
+		{in: "2>abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.in)
+		switch {
+		case tt.wantErr && err == nil:
+			t.Errorf("ParseFilter(%q) = %+v, want an error", tt.in, f)
+		case !tt.wantErr && err != nil:
+			t.Errorf("ParseFilter(%q) = %v", tt.in, err)
+		case !tt.wantErr && (f.Col != tt.col || f.Op != tt.op || f.Value != tt.value):
+			t.Errorf("ParseFilter(%q) = %d %q %q, want %d %q %q", tt.in, f.Col, f.Op, f.Value, tt.col, tt.op, tt.value)
+		case tt.op == "~" && f.Regexp == nil:
+			t.Errorf("ParseFilter(%q) has no regexp", tt.in)
+		}
+	}
+}
+
+func TestFilterRows(t *testing.T) {
+	rows := [][]string{
+		{"apple", "3", "red"},
+		{"banana", "12", "yellow"},
+		{"cherry", "n/a", "red"},
+		{"date", "-4.5"},
+		{"elderberry", "100", "purple"},
+	}
+	names := func(rows [][]string) []string {
+		var names []string
+		for _, row := range rows {
+			names = append(names, row[0])
+		}
+		return names
+	}
+
+	tests := []struct {
+		filters []string
+		want    []string
+	}{
+		{[]string{"1~an"}, []string{"banana"}},
+		{[]string{"1~^(apple|date)$"}, []string{"apple", "date"}},
+		{[]string{"3~^$"}, []string{"date"}},
+		{[]string{"3=red"}, []string{"apple", "cherry"}},
+		{[]string{"2>3"}, []string{"banana", "elderberry"}},
+		{[]string{"2>=3"}, []string{"apple", "banana", "elderberry"}},
+		{[]string{"2<0"}, []string{"date"}},
+		{[]string{"2<=12"}, []string{"apple", "banana", "date"}},
+		{[]string{"2>=3", "3=red"}, []string{"apple"}},
+		{[]string{"2>1000"}, nil},
+		{[]string{"1~e", "2<50", "3~^r"}, []string{"apple"}},
+	}
+	for _, tt := range tests {
+		var filters []Filter
+		for _, v := range tt.filters {
+			f, err := ParseFilter(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filters = append(filters, f)
+		}
+		if got := names(filterRows(rows, filters, false)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("filterRows(%q) = %q, want %q", tt.filters, got, tt.want)
+		}
+	}
+}
+
+func TestRenderFilter(t *testing.T) {
+	const in = "name\tqty\napple\t3\nbanana\t12\ncherry\t7\ntotal\t22\n"
+	f, err := ParseFilter("2>5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := renderTSV(t, in, Options{Header: true, Footer: true, Filters: []Filter{f}})
+	if want := "name\tqty\nbanana\t12\ncherry\t7\ntotal\t22\n"; got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := RenderString(in, Options{Filters: []Filter{{Col: 0, Op: "!"}}}); err == nil {
+		t.Error("an unrecognized comparison rendered without an error")
+	}
+}
+
+func TestFilterRowsIgnoreCase(t *testing.T) {
+	rows := [][]string{{"Apple", "RED"}, {"apple", "red"}, {"APRICOT", "Orange"}, {"banana", "yellow"}}
+	tests := []struct {
+		filter string
+		fold   bool
+		want   int
+	}{
+		{"1~^ap", false, 1},
+		{"1~^ap", true, 3},
+		{"1~^AP", true, 3},
+		{"1~(?i)^ap", false, 3},
+		{"2=red", false, 1},
+		{"2=red", true, 2},
+		{"2=ORANGE", true, 1},
+		{"1~ZZZ", true, 0},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := filterRows(rows, []Filter{f}, tt.fold); len(got) != tt.want {
+			t.Errorf("filterRows(%q, %v) = %q, want %d rows", tt.filter, tt.fold, got, tt.want)
+		}
+	}
+
+	// Folding the filter's expression leaves the filter itself unchanged.
+	f, _ := ParseFilter("1~^ap")
+	filterRows(rows, []Filter{f}, true)
+	if f.Regexp.String() != "^ap" {
+		t.Errorf("filter expression changed to %q", f.Regexp)
+	}
+}
@@ -0,0 +1,110 @@
+package ftable
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseFixedWidths parses a comma-separated list of the widths, in characters, of fixed-width input columns, such
+// as "8,12,6". Each width ends its column; any text past the last is left to a column of its own.
+func ParseFixedWidths(v string) ([]int, error) {
+	var widths []int
+	for _, field := range strings.Split(v, ",") {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid column width %q", field)
+		}
+		widths = append(widths, n)
+	}
+	return widths, nil
+}
+
+// copyFixed parses r as fixed-width columns and writes them to w as tab-separated lines. Each line is sliced into
+// columns of widths characters, after its tabs are expanded with stops every tabWidth columns, and any text past
+// the last of them, on any line but spaces, forms one more column extending to the end of the line. If widths is nil, columns are instead taken to begin after each run of
+// character positions that hold spaces on every line, as in the output of ls -l or ps. Cells are trimmed of
+// surrounding spaces.
+func copyFixed(w io.Writer, r io.Reader, widths []int, tabWidth int) error {
+	var lines [][]rune
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			lines = append(lines, []rune(expandTabs(line, tabWidth)))
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	starts := fixedStarts(lines)
+	if widths != nil {
+		starts = []int{0}
+		for _, n := range widths {
+			starts = append(starts, starts[len(starts)-1]+n)
+		}
+		if !overflows(lines, starts[len(starts)-1]) {
+			starts = starts[:len(starts)-1]
+		}
+	}
+
+	for _, line := range lines {
+		cells := make([]string, len(starts))
+		for i, start := range starts {
+			end := len(line)
+			if i+1 < len(starts) && starts[i+1] < end {
+				end = starts[i+1]
+			}
+			if start < end {
+				cells[i] = strings.TrimSpace(string(line[start:end]))
+			}
+		}
+		if _, err := io.WriteString(w, strings.Join(cells, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// overflows reports whether any of lines has text other than spaces past its first n characters.
+func overflows(lines [][]rune, n int) bool {
+	for _, line := range lines {
+		if len(line) > n && strings.TrimSpace(string(line[n:])) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// fixedStarts returns the positions at which the columns of lines begin: each that is not a space on some line,
+// following the first position or one that is a space, or past the end, on every line.
+func fixedStarts(lines [][]rune) []int {
+	var gutter []bool
+	for _, line := range lines {
+		for len(gutter) < len(line) {
+			gutter = append(gutter, true)
+		}
+		for i, r := range line {
+			if r != ' ' {
+				gutter[i] = false
+			}
+		}
+	}
+
+	var starts []int
+	for i := range gutter {
+		if !gutter[i] && (i == 0 || gutter[i-1]) {
+			starts = append(starts, i)
+		}
+	}
+	if starts == nil {
+		starts = []int{0}
+	}
+	return starts
+}
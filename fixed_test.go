@@ -0,0 +1,137 @@
+package ftable
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFixedWidths(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []int
+		wantErr bool
+	}{
+		{"8,12,6", []int{8, 12, 6}, false},
+		{"5", []int{5}, false},
+		{"8,,6", nil, true},
+		{"8,0", nil, true},
+		{"8,-1", nil, true},
+		{"auto", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFixedWidths(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFixedWidths(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseFixedWidths(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCopyFixed(t *testing.T) {
+	const report = "" +
+		"ACCT    NAME        AMOUNT\n" +
+		"0001    Smith, J      12.50\n" +
+		"0002    Doe         1200.00\n" +
+		"0003    Müller         3.75\r\n" +
+		"0004\n"
+	tests := []struct {
+		name   string
+		in     string
+		widths []int
+		want   string
+	}{
+		{
+			"explicit", report, []int{8, 12, 7},
+			"ACCT\tNAME\tAMOUNT\n0001\tSmith, J\t12.50\n0002\tDoe\t1200.00\n0003\tMüller\t3.75\n0004\t\t\n",
+		},
+		{
+			"text past the last width", "abcdefgh\nij\n", []int{2, 2},
+			"ab\tcd\tefgh\nij\t\t\n",
+		},
+		{
+			"last width ends its column", "ab  cd  \nef\n", []int{4, 2},
+			"ab\tcd\nef\t\n",
+		},
+		{
+			"last width splits a cell", "1234567\n", []int{3, 2},
+			"123\t45\t67\n",
+		},
+		{
+			"tabs", "a\tb\n", []int{8, 1},
+			"a\tb\n",
+		},
+		{
+			"auto", "USER   PID COMMAND\nroot     1 init\nalice 4242 vim notes\n", nil,
+			"USER\tPID\tCOMMAND\nroot\t1\tinit\nalice\t4242\tvim notes\n",
+		},
+		{
+			"auto with a single column", "one\ntwo\n", nil,
+			"one\ntwo\n",
+		},
+		{
+			"auto empty", "", nil,
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := copyFixed(&sb, strings.NewReader(tt.in), tt.widths, 8); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFixedStarts(t *testing.T) {
+	tests := []struct {
+		lines []string
+		want  []int
+	}{
+		{[]string{"ab  cd", "e   f"}, []int{0, 4}},
+		{[]string{"  ab cd", "  e  f "}, []int{2, 5}},
+		{[]string{"ab cd", "abcdef"}, []int{0}},
+		{[]string{"a   b", "a", "a  xb"}, []int{0, 3}},
+		{nil, []int{0}},
+	}
+	for _, tt := range tests {
+		var lines [][]rune
+		for _, line := range tt.lines {
+			lines = append(lines, []rune(line))
+		}
+		if got := fixedStarts(lines); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("fixedStarts(%q) = %v, want %v", tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestRenderFixed(t *testing.T) {
+	got, err := RenderString("ID  NAME\n1   Smith, J\n22  Doe\n", Options{Fixed: []int{4, 10}, Box: true, Header: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "┏━━━━┳━━━━━━━━━━┓\n" +
+		"┃ ID ┃ NAME     ┃\n" +
+		"┡━━━━╇━━━━━━━━━━┩\n" +
+		"│ 1  │ Smith, J │\n" +
+		"│ 22 │ Doe      │\n" +
+		"└────┴──────────┘\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderFixedAuto(t *testing.T) {
+	got := renderTSV(t, "USER   PID\nroot     1\nalice 4242\n", Options{FixedAuto: true})
+	if want := "USER\tPID\nroot\t1\nalice\t4242\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -1,184 +1,819 @@
-// Command ftable reads all text from stdin and passes it through a text/tabwriter to produce pretty columnar
-// output. It will optionally wrap all output in box drawing glyphs if the -box flag is set, with a header row
-// when -header is passed in addition to -box.
-package main
+// Package ftable formats tab-separated (or otherwise delimited) text as aligned columns, either through a
+// text/tabwriter or as a table drawn with box-drawing characters, or as a Markdown, HTML, LaTeX,
+// reStructuredText, org-mode, or JSON table.
+//
+// A Table reads its input with ReadFrom and writes the formatted table with Render. Here, the zero value of any
+// Options not set draws a box with one space of padding around each cell:
+//
+//	t := &ftable.Table{Options: ftable.Options{Box: true, Header: true}}
+//	if _, err := t.ReadFrom(os.Stdin); err != nil {
+//		// ...
+//	}
+//	if err := t.Render(os.Stdout); err != nil {
+//		// ...
+//	}
+package ftable
 
 import (
 	"bytes"
-	"flag"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
-	"sort"
+	"regexp"
 	"strings"
 	"text/tabwriter"
+	"unicode/utf8"
 )
 
-const termChar byte = 0x0e
+// ErrEmpty is returned by Render, having written nothing, when a box would be drawn around empty or
+// whitespace-only input.
+var ErrEmpty = errors.New("ftable: no input to draw a box around")
 
-type tabFlags uint
+// Options holds the settings for formatting a Table. The zero value formats input through a tabwriter with no
+// minimum width, padding, or tab width, padding with spaces, or draws a box with a space on either side of each
+// cell.
+type Options struct {
+	// MinWidth, TabWidth, Padding, PadChar, and Flags are passed to the tabwriter, as with tabwriter.NewWriter.
+	// PadChar may be any single character in box mode, and defaults to a space if zero. In box mode, a Padding
+	// of less than one is taken as one. Of the tabwriter's flags, only AlignRight and DiscardEmptyColumns apply
+	// with Box.
+	MinWidth, TabWidth, Padding int
+	PadChar                     rune
+	Flags                       uint
 
-var flagBits = map[uint]string{
-	tabwriter.FilterHTML:          "filter-html",
-	tabwriter.StripEscape:         "strip-escape",
-	tabwriter.AlignRight:          "align-right",
-	tabwriter.DiscardEmptyColumns: "discard-empty",
-	tabwriter.TabIndent:           "tab-indent",
-	tabwriter.Debug:               "debug",
+	// Box draws the table with box-drawing characters. Header and Footer treat the first and last rows as the
+	// table's header and footer, drawn with heavy borders in box mode. RowLines draws separators between rows
+	// in box mode.
+	Box, Header, Footer, RowLines bool
+	// RowLinesEvery, if greater than zero, draws separators only after every RowLinesEvery data rows in box mode,
+	// whether or not RowLines is set.
+	RowLinesEvery int
+	// Indent, if greater than zero, begins every line of text, box, and markup output with Indent spaces. Data
+	// formats, such as csv and json, are never indented.
+	Indent int
+	// Center indents text, box, and markup output further to center it within Width, if greater than zero. Output
+	// as wide as Width or wider is not centered.
+	Center bool
+
+	// HeaderStyle is the style in which a box draws its header: "heavy" (or empty) for heavy borders, or "double"
+	// or "light" for light borders with a double or light line, respectively, below it.
+	HeaderStyle string
+
+	// RepeatHeader, if greater than zero, repeats the header of text and box output before every RepeatHeader data
+	// rows after the first, so that it stays in view through long tables.
+	RepeatHeader int
+	// NoOuter draws a box without its outer frame, keeping only the lines between its columns and rows.
+	NoOuter bool
+	// Compact draws a box with one less space around each cell, so that, with the default Padding of one, cells
+	// touch their dividers.
+	Compact bool
+	// HeaderColor, if not empty, holds the SGR parameters, as returned by ParseColor, with which to color the
+	// header row of text and box output. Colors are applied after cells are laid out, so they don't affect
+	// column widths.
+	HeaderColor string
+	// Zebra, if either is not empty, holds the SGR parameters with which to color alternating data rows of text
+	// and box output, starting from the first row after the header. An empty entry leaves its rows uncolored.
+	Zebra [2]string
+
+	// Style is the style in which to draw boxes, one of those returned by BoxStyles, or StyleLight if empty.
+	Style Style
+
+	// Title, if not empty, is a caption centered over text and box output, above the table or, if TitlePos is
+	// "bottom", below it. TitlePos is "top" if empty.
+	Title, TitlePos string
+	// TitleInline embeds the Title in the top border of a box, in place of a caption, unless NoOuter leaves it
+	// without one.
+	TitleInline bool
+
+	// Width, if greater than zero, is the maximum width of a box, including any Indent. Cells are wrapped to fit
+	// within it.
+	Width int
+	// MaxCol, if greater than zero, is the maximum display width of a cell. Wider cells are truncated, ending
+	// in Ellipsis, which defaults to "…" if empty and also ends titles truncated to fit a box's top border.
+	MaxCol   int
+	Ellipsis string
+	// ColWidths, if not nil, clamp the widths of particular columns of text and box output.
+	ColWidths []ColWidth
+
+	// SortCol, if greater than zero, is the 1-based column by which to sort rows, other than the header and
+	// footer. Rows are sorted lexically, or by numeric value if SortNumeric is set, or in natural order (with
+	// runs of digits compared by their values) if SortNatural is set.
+	SortCol     int
+	SortNumeric bool
+	SortNatural bool
+	SortReverse bool
+	// SortKeys, if not nil, sort rows by more than one column in place of SortCol: by the first key, and then by
+	// each following key among rows that are equal by those before it.
+	SortKeys []SortKey
+
+	// GroupBy, if greater than zero, is the 1-based column by which to group data rows. Rows are stably sorted by
+	// it, after any other sorting, and its cell is left blank in all but the first row of each group. With
+	// Aggregates, each group is followed by a subtotal row, which is not numbered, striped, or checked as data
+	// rows are. Data formats, such as csv, are only sorted, keeping every key and adding no subtotals.
+	GroupBy int
+
+	// Collapse, if not nil, are the 0-based indices of input columns in which to blank each data cell that repeats
+	// the one above it, after any sorting and grouping, so that runs of equal cells read as one.
+	Collapse []int
+
+	// TrimLeft and TrimRight remove the whitespace at the start and end, respectively, of each cell before any
+	// other transformation.
+	TrimLeft, TrimRight bool
+	// KeepIndent keeps the leading whitespace of the first column, such as the indentation of a tree: TrimLeft
+	// leaves it in the cells of the first input column, and the cells of the first output column are aligned,
+	// padded, and wrapped after it.
+	KeepIndent bool
+
+	// Transpose swaps the rows and columns of the input before any other transformation, so that the header
+	// and footer are the first and last rows of the transposed table: with Header, the input's first column
+	// becomes the header.
+	Transpose bool
+
+	// Dates, if not nil, rewrite the dates in the data cells of columns with other layouts, before any filtering or
+	// sorting. Dates are parsed in the layout DateInput, if not empty, or in any of a number of common layouts,
+	// such as RFC 3339's; cells that are not dates are left unchanged.
+	Dates     []DateFormat
+	DateInput string
+
+	// Humanize, if not nil, are the 0-based indices of input columns of numbers of bytes to write as sizes in
+	// larger units, such as "1.5 KiB", after any aggregation. Dehumanize are those of columns of such sizes to
+	// write as numbers of bytes, before any filtering or sorting. Units are powers of 1024 or, if SizeSI is set,
+	// of 1000, such as "1.5 kB"; units such as "KiB" are always powers of 1024.
+	Humanize   []int
+	Dehumanize []int
+	SizeSI     bool
+
+	// Checks, if not nil, are the 0-based indices of input columns of boolean values, such as "yes" and "no" or
+	// "true" and "false", to write as check and cross marks, such as "✓" and "✗", or "Y" and "N" with StyleASCII.
+	// Their columns are centered, unless Aligns sets their alignments.
+	Checks []int
+
+	// IgnoreCase sorts rows and filters them by "=" and "~" conditions without regard to case.
+	IgnoreCase bool
+
+	// Filters, if not nil, are conditions that data rows must all meet to be kept. The header and footer are
+	// always kept, and rows are filtered before they are sorted or aggregated.
+	Filters []Filter
+
+	// Unique drops data rows that duplicate an earlier one in every cell, and UniqueBy, if greater than zero, those
+	// that duplicate an earlier one in the 1-based column UniqueBy, after any filtering and before sorting.
+	Unique   bool
+	UniqueBy int
+
+	// Head and Tail, if greater than zero, limit the data rows to the first Head and then the last Tail of them,
+	// after any filtering and sorting and before aggregation.
+	Head, Tail int
+
+	// Link, if greater than zero, is the 1-based column whose data cells are made terminal hyperlinks (with OSC 8
+	// escape sequences) to the URLs in the 1-based column LinkURL or, if LinkURL is zero, to their own text. Link
+	// and LinkURL name input columns, as Cols does. Data formats, such as json, are never linked.
+	Link, LinkURL int
+
+	// Highlights, if not nil, select data cells to color with the SGR parameters HighlightColor, or bold if it is
+	// empty, such as the greatest number in a column. Highlights name input columns, as Cols does. Like Match, it
+	// does not apply to data formats.
+	Highlights     []Highlight
+	HighlightColor string
+	// Match, if not nil, highlights every match of it within the text of cells with the SGR parameters MatchColor,
+	// or bold red if it is empty.
+	Match      *regexp.Regexp
+	MatchColor string
+
+	// Cols, if not nil, are the 0-based indices of the input columns to output, in order. Exclude, if not nil, are
+	// those of input columns not to output, whether or not Cols names them.
+	Cols    []int
+	Exclude []int
+
+	// Aggregates, if not nil, are computed over the data rows and added to the table as its footer, in place of
+	// the last input row: Footer is implied. A column may have only one aggregate.
+	Aggregates []Aggregate
+
+	// PadRows pads every row with empty cells to the length of the longest row. Strict instead makes Render
+	// return an error, having written nothing, if any row differs in length from the first.
+	PadRows, Strict bool
+
+	// Empty, if not empty, is a placeholder, such as "-", written in place of empty data cells. Placeholders do not
+	// keep AutoNum from detecting a column as numeric.
+	Empty string
+
+	// VAlign places cells within rows made taller by other cells with line breaks, or wrapped to fit Width: one
+	// of "top", "middle", or "bottom", or "top" if empty.
+	VAlign string
+
+	// Grouping, if not zero, is inserted between each group of three integer digits of the numbers in columns
+	// whose data cells are all numeric, as in "1,234,567".
+	Grouping rune
+
+	// Number adds a column numbering the data rows, starting from NumberFrom.
+	Number     bool
+	NumberFrom int
+
+	// From names the input format: "markdown" to parse input as a GitHub-flavored Markdown table, ignoring CSV and
+	// Delim, or empty to parse it as they describe.
+	From string
+
+	// Fixed, if not nil, holds the widths, in characters, of fixed-width input columns, which are parsed in place
+	// of CSV and delimited columns. Text past the last of them becomes one more column, extending to the end of
+	// each line. FixedAuto instead infers the columns from the character positions that hold spaces on every line.
+	Fixed     []int
+	FixedAuto bool
+
+	// RecordSep, if not empty, separates input rows in place of line endings, which then break lines within cells.
+	// Input is split into rows by it before anything else, so it separates rows even within quoted CSV fields.
+	RecordSep string
+
+	// Delim, if not empty, separates input columns in place of tabs, or is DelimAuto to have it detected from the
+	// input. DelimRE, if not nil, takes precedence over Delim and separates columns wherever it matches.
+	Delim   string
+	DelimRE *regexp.Regexp
+
+	// Format names the output format: "text" (or empty) for tabwriter or box output; one of the table markup
+	// formats "markdown", "html", "latex", "rst", "org", or "adoc" (AsciiDoc); "json", or "jsonl" for JSON Lines;
+	// "csv" or "tsv" for delimited records, or "nul" for tab-separated records ending in NUL bytes; or "sql" for
+	// INSERT statements. The json, jsonl, and sql formats require Header.
+	Format string
+
+	// SQLTable names the table into which the sql format inserts rows, and is required by it. SQLNulls inserts
+	// empty cells as NULL, rather than as empty strings.
+	SQLTable string
+	SQLNulls bool
+
+	// Aligns, if not nil, sets the alignment of each column, overriding the tabwriter's AlignRight flag.
+	Aligns []Alignment
+	// AutoNum right-aligns columns whose cells are all numeric, unless Aligns sets their alignment.
+	AutoNum bool
+
+	// StripColor removes ANSI escape sequences from input.
+	StripColor bool
+	// ShowControl, if not empty, replaces the ASCII control characters in cells, which could otherwise move the
+	// cursor or be measured wrongly, with "caret" notation, such as "^G", or Unicode control pictures, such as
+	// "␇", with "picture". The escape sequences of colors and hyperlinks are kept.
+	ShowControl string
+
+	// SkipBlank drops input lines that are empty or only spaces, and Comment, if not empty, drops input lines
+	// beginning with it, such as "#". With CSV, lines within quoted fields are kept regardless.
+	SkipBlank bool
+	Comment   string
+
+	// CSV parses input as CSV records using Comma as the field separator. If Comma is zero, it is the delimiter
+	// detected with a Delim of DelimAuto, or ','.
+	CSV   bool
+	Comma rune
+
+	// dataIndex, set by transform once GroupBy has added subtotal rows, holds the index of each row of the table
+	// among its data rows, or -1 for the header, the footer, and subtotal rows, which are neither numbered nor
+	// striped.
+	dataIndex []int
 }
 
-var flagNames = map[string]uint{}
+// Table formats its input according to its Options, which may be set directly or with the Option functions
+// passed to New. Options must be set before any input is read, since they determine how input is split into
+// columns.
+//
+// A Table holds all of its input in memory until it is rendered, since the width of every column may depend on
+// its last row, and rendering needs roughly as much memory again to hold the table's rows, so formatting large
+// inputs costs memory in proportion to their size. (The tabwriter used for text output buffers its input in the
+// same way.)
+type Table struct {
+	Options
+
+	input bytes.Buffer
+	// widths are the widths of the columns last rendered.
+	widths []int
+}
 
-func init() {
-	for b, n := range flagBits {
-		flagNames[n] = b
+// ReadFrom reads r until EOF, adding its lines, converted to tab-separated columns as described by t's Options,
+// to t's input. Each call parses r as a separate input; for example, a CSV record cannot span two readers. If r is
+// gzip-compressed, it is decompressed as it is read.
+func (t *Table) ReadFrom(r io.Reader) (int64, error) {
+	// Files are read into a buffer grown to fit them up front, rather than doubling it as they're read.
+	if f, ok := r.(interface{ Stat() (os.FileInfo, error) }); ok {
+		if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() && fi.Size() < math.MaxInt32 {
+			t.input.Grow(int(fi.Size()))
+		}
 	}
+
+	n := int64(t.input.Len())
+	err := t.copyInput(&t.input, r)
+	return int64(t.input.Len()) - n, err
 }
 
-func (t *tabFlags) Set(v string) error {
-	flags := uint(*t)
-	for _, name := range strings.Split(v, ",") {
-		if b, ok := flagNames[v]; ok {
-			flags |= b
+// Render writes t's input to w as a formatted table. It returns ErrEmpty, having written nothing, if t is boxed
+// and its input is empty or all whitespace, and otherwise the first error encountered writing to w.
+func (t *Table) Render(w io.Writer) error {
+	opts := t.Options
+	if err := opts.init(); err != nil {
+		return err
+	}
+
+	if opts.Box && len(bytes.TrimSpace(t.input.Bytes())) == 0 {
+		return ErrEmpty
+	}
+
+	rows := splitRows(t.input.Bytes())
+	if opts.Strict {
+		for n, row := range rows {
+			if len(row) != len(rows[0]) {
+				return fmt.Errorf("ftable: row %d has %d fields, but row 1 has %d", n+1, len(row), len(rows[0]))
+			}
+		}
+	}
+
+	// A centered table is only indented once it has been drawn and its width is known.
+	dest := w
+	var centered bytes.Buffer
+	center := opts.Center && opts.Width > 0 && !opts.dataFormat()
+	switch {
+	case center:
+		w = &centered
+	case opts.Indent > 0 && !opts.dataFormat():
+		w = &indenter{w: w, indent: strings.Repeat(" ", opts.Indent)}
+	}
+
+	ew := &errWriter{w: w}
+	rows = opts.transform(rows)
+	t.widths = columnWidths(rows)
+	switch opts.Format {
+	case "markdown":
+		var aligns []Alignment
+		if opts.aligned() {
+			aligns = opts.columnAlignments(rows)
+		}
+		writeMarkdown(ew, rows, aligns, opts.Header)
+	case "html":
+		writeHTML(ew, rows, opts.Header)
+	case "latex":
+		writeLaTeX(ew, rows, opts.columnAlignments(rows), opts.Header, opts.Footer)
+	case "rst":
+		writeRST(ew, rows, opts.Header)
+	case "adoc":
+		var aligns []Alignment
+		if opts.aligned() {
+			aligns = opts.columnAlignments(rows)
+		}
+		writeAsciiDoc(ew, rows, aligns, opts.Header, opts.Footer)
+	case "org":
+		writeOrg(ew, rows, opts.columnAlignments(rows), opts.Header, opts.Footer)
+	case "json":
+		if err := writeJSON(ew, rows); err != nil {
+			return err
+		}
+	case "jsonl":
+		if err := writeJSONLines(ew, rows); err != nil {
+			return err
+		}
+	case "sql":
+		if err := writeSQL(ew, rows, opts.SQLTable, opts.SQLNulls); err != nil {
+			return err
+		}
+	case "csv":
+		if err := writeDelimited(ew, rows, ','); err != nil {
+			return err
+		}
+	case "tsv":
+		if err := writeDelimited(ew, rows, '\t'); err != nil {
+			return err
+		}
+	case "nul":
+		writeNUL(ew, rows)
+	default:
+		var out io.Writer = ew
+		var buf bytes.Buffer
+		if opts.Title != "" && !(opts.Box && opts.TitleInline && !opts.NoOuter) {
+			out = &buf
+		}
+
+		if opts.Box {
+			t.widths = writeBox(out, rows, &opts)
 		} else {
-			return fmt.Errorf("unrecognized flag %q", name)
+			writeText(out, rows, &opts)
+		}
+
+		if out == &buf {
+			writeTitled(ew, buf.String(), opts.Title, opts.TitlePos == "bottom")
 		}
 	}
-	*t = tabFlags(flags)
-	return nil
-}
 
-func (t *tabFlags) String() string {
-	flags := []string{}
-	ui := uint(*t)
-	for b, n := range flagBits {
-		if ui&b == b {
-			flags = append(flags, n)
+	if center {
+		indent := opts.Indent
+		if n := (opts.Width - opts.Indent - maxLineWidth(centered.String())) / 2; n > 0 {
+			indent += n
 		}
+		_, err := (&indenter{w: dest, indent: strings.Repeat(" ", indent)}).Write(centered.Bytes())
+		return err
 	}
-	sort.Strings(flags)
-	return strings.Join(flags, ",")
+	return ew.err
 }
 
-func main() {
-	var mwidth, tabwidth, padding int
-	var padchar string
-	var flags tabFlags
-	var box, header, rowlines bool
+// maxLineWidth returns the display width of the widest line of s.
+func maxLineWidth(s string) int {
+	width := 0
+	for _, line := range strings.Split(s, "\n") {
+		if n := displayWidth(line); n > width {
+			width = n
+		}
+	}
+	return width
+}
 
-	flag.BoolVar(&box, "box", false, "whether to box the output with box-drawing characters")
-	flag.BoolVar(&header, "header", false, "whether the first line of boxed output is a header box")
-	flag.BoolVar(&rowlines, "rowlines", false, "whether to insert row separators in box mode")
-	flag.IntVar(&mwidth, "minwidth", 0, "the minimum `width` of a column in bytes")
-	flag.IntVar(&tabwidth, "tabwidth", 8, "the `width` of a tab in bytes")
-	flag.IntVar(&padding, "padding", 1, "`padding`")
-	flag.StringVar(&padchar, "padchar", " ", "the padding `char` to use; only the first byte is used if a multibyte string is provided")
-	flag.Var(&flags, "flags", "any comma-separated combination of the flags: filter-html, strip-escape, align-right, discard-empty, tab-indent, debug")
-	flag.Parse()
+// writeText writes rows to w through a tabwriter, aligning their cells per column beforehand if opts require it.
+// Rows with cells of more than one line are written as that many lines.
+func writeText(w io.Writer, rows [][]string, opts *Options) {
+	var aligns []Alignment
+	if opts.aligned() {
+		aligns = opts.columnAlignments(rows)
+	}
 
-	if len(padchar) != 1 {
-		fmt.Fprintf(os.Stderr, "invalid padchar of length %d", len(padchar))
-		os.Exit(1)
+	nrows := len(rows)
+	rows, from := expandLines(rows, opts.VAlign)
+	if aligns != nil {
+		alignRows(rows, aligns, opts.MinWidth-opts.Padding, opts.PadChar, opts.indentCol())
+	}
+	if opts.ColWidths != nil {
+		padColumns(rows, opts.ColWidths, aligns, opts.PadChar, opts.indentCol())
 	}
 
-	if !box {
-		w := tabwriter.NewWriter(os.Stdout, mwidth, tabwidth, padding, padchar[0], uint(flags))
-		defer w.Flush()
+	if opts.RepeatHeader > 0 && opts.Header {
+		var header [][]string
+		for n := 0; n < len(rows) && from[n] == 0; n++ {
+			header = append(header, rows[n])
+		}
 
-		if _, err := io.Copy(w, os.Stdin); err != nil {
-			fmt.Fprintf(os.Stderr, "error reading from stdin: %v", err)
-			os.Exit(1)
+		var repeated [][]string
+		var repeatedFrom []int
+		for n, row := range rows {
+			if (n == 0 || from[n] != from[n-1]) && opts.repeatsHeader(from[n], nrows) {
+				repeated = append(repeated, header...)
+				repeatedFrom = append(repeatedFrom, make([]int, len(header))...)
+			}
+			repeated, repeatedFrom = append(repeated, row), append(repeatedFrom, from[n])
 		}
-		return
+		rows, from = repeated, repeatedFrom
 	}
 
+	// The tabwriter would count escape sequences in the widths of cells, so cells are written to it without them
+	// and have them restored once it has padded them.
+	var plain [][]string
+	for n, row := range rows {
+		for i, cell := range row {
+			if strings.IndexByte(cell, '\x1b') < 0 {
+				continue
+			}
+			if plain == nil {
+				plain = make([][]string, len(rows))
+				for n, row := range rows {
+					plain[n] = append([]string(nil), row...)
+				}
+			}
+			plain[n][i] = stripANSI(cell)
+		}
+	}
+	text := rows
+	if plain != nil {
+		text = plain
+	}
+
+	out := w
 	var buf bytes.Buffer
-	if _, err := buf.ReadFrom(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error reading from stdin: %v", err)
-		os.Exit(1)
+	if opts.colored() || plain != nil {
+		out = &buf
+	}
+
+	tw := tabwriter.NewWriter(out, opts.MinWidth, opts.TabWidth, opts.Padding, byte(opts.PadChar), opts.Flags)
+	tw.Write(joinRows(text))
+	tw.Flush()
+
+	// Rows are colored as whole lines once the tabwriter has padded them, for the same reason.
+	if out == &buf && buf.Len() > 0 {
+		lines := strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		for n, line := range lines {
+			line = strings.TrimSuffix(line, "\n")
+			if plain != nil {
+				line = restoreEscapes(line, plain[n], rows[n])
+			}
+			io.WriteString(w, colorize(line, opts.rowColor(from[n], nrows))+"\n")
+		}
 	}
+}
 
-	bs := buf.Bytes()
-	if tab := []byte("\t"); uint(flags)&tabwriter.AlignRight == tabwriter.AlignRight {
-		bs = bytes.Replace(bs, tab, []byte{' ', termChar, ' ', '\t'}, -1)
+// restoreEscapes returns line, a row of the cells plain padded by the tabwriter, with each cell replaced by its
+// counterpart in cells, which has the same text but for any escape sequences.
+func restoreEscapes(line string, plain, cells []string) string {
+	var sb strings.Builder
+	for i, cell := range plain {
+		n := strings.Index(line, cell)
+		if n < 0 {
+			break
+		}
+		sb.WriteString(line[:n])
+		sb.WriteString(cells[i])
+		line = line[n+len(cell):]
+	}
+	sb.WriteString(line)
+	return sb.String()
+}
+
+// writeTitled writes table to w with a title line centered over its widest line, above the table or, if bottom
+// is set, below it.
+func writeTitled(w io.Writer, table, title string, bottom bool) {
+	width := maxLineWidth(table)
+	if n := (width - displayWidth(title)) / 2; n > 0 {
+		title = strings.Repeat(" ", n) + title
+	}
+
+	if bottom {
+		io.WriteString(w, table+title+"\n")
 	} else {
-		bs = bytes.Replace(bs, tab, []byte{'\t', termChar, ' '}, -1)
+		io.WriteString(w, title+"\n"+table)
 	}
+}
 
-	var outbuf bytes.Buffer
-	w := tabwriter.NewWriter(&outbuf, mwidth, tabwidth, padding, padchar[0], uint(flags))
-	w.Write(bs)
-	w.Flush()
+// FieldCounts returns the number of fields in each row of t's input, as split by its Options, before any
+// transformation.
+func (t *Table) FieldCounts() []int {
+	rows := splitRows(t.input.Bytes())
+	counts := make([]int, len(rows))
+	for n, row := range rows {
+		counts[n] = len(row)
+	}
+	return counts
+}
 
-	var lines = bytes.Split(outbuf.Bytes(), []byte("\n"))
-	maxLen := 0
-	separators := map[int]struct{}{}
-	for _, bs := range lines {
-		if reallen := len(bytes.Runes(bs)); maxLen < reallen {
-			maxLen = reallen
-		}
+// Widths returns the display widths of the columns of the table last rendered by Render, excluding any padding
+// and after any truncation and wrapping, or nil if none has been.
+func (t *Table) Widths() []int {
+	return t.widths
+}
 
-		for i, r := range bytes.Runes(bs) {
-			if r == rune(termChar) {
-				separators[i] = struct{}{}
-			}
+// RenderString formats input as a table according to opts and returns it as a string. It is equivalent to
+// reading input into a Table with opts and rendering it, as the ftable command does.
+func RenderString(input string, opts Options) (string, error) {
+	t := &Table{Options: opts}
+	if _, err := t.ReadFrom(strings.NewReader(input)); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := t.Render(&sb); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// Validate returns an error if opts are inconsistent or name an unrecognized format, style, or position, the
+// same error Render would return for them. It lets callers reject bad options before reading any input.
+func (opts Options) Validate() error {
+	return opts.init()
+}
+
+// init fills in the defaults of opts and checks that its settings are consistent.
+func (opts *Options) init() error {
+	if opts.PadChar == 0 {
+		opts.PadChar = ' '
+	}
+	if opts.Ellipsis == "" {
+		opts.Ellipsis = "…"
+	}
+	if opts.Format == "" {
+		opts.Format = "text"
+	}
+	if opts.Style == "" {
+		opts.Style = StyleLight
+	}
+	if opts.HighlightColor == "" {
+		opts.HighlightColor = "1"
+	}
+	if opts.MatchColor == "" {
+		opts.MatchColor = "1;31"
+	}
+	if opts.aligns() {
+		opts.Flags &^= tabwriter.AlignRight
+	}
+	if opts.Aggregates != nil {
+		opts.Footer = true
+	}
+	if err := validateAggregates(opts.Aggregates); err != nil {
+		return err
+	}
+
+	if _, ok := boxStyles[opts.Style]; !ok {
+		return fmt.Errorf("ftable: unrecognized box style %q", opts.Style)
+	}
+
+	for _, key := range opts.SortKeys {
+		switch key.Compare {
+		case "", "numeric", "natural", "lexical":
+		default:
+			return fmt.Errorf("ftable: unrecognized sort comparison %q", key.Compare)
 		}
 	}
 
-	applySep := func(sep []byte, r rune, force bool) []byte {
-		runes := bytes.Runes(sep)
+	switch opts.ShowControl {
+	case "", "caret", "picture":
+	default:
+		return fmt.Errorf("ftable: unrecognized control character notation %q", opts.ShowControl)
+	}
 
-		for i := range separators {
-			if len(runes) > i && (force || runes[i] == rune(termChar)) {
-				runes[i] = r
-			}
+	switch opts.From {
+	case "", "markdown":
+	default:
+		return fmt.Errorf("ftable: unrecognized input format %q", opts.From)
+	}
+
+	switch opts.TitlePos {
+	case "", "top", "bottom":
+	default:
+		return fmt.Errorf("ftable: unrecognized title position %q", opts.TitlePos)
+	}
+
+	switch opts.HeaderStyle {
+	case "", "heavy", "double", "light":
+	default:
+		return fmt.Errorf("ftable: unrecognized header style %q", opts.HeaderStyle)
+	}
+
+	switch opts.VAlign {
+	case "", "top", "middle", "bottom":
+	default:
+		return fmt.Errorf("ftable: unrecognized vertical alignment %q", opts.VAlign)
+	}
+
+	for i := range opts.Filters {
+		if err := opts.Filters[i].validate(); err != nil {
+			return err
 		}
+	}
 
-		return []byte(string(runes))
+	switch opts.Format {
+	case "text":
+		// The tabwriter only pads with a single byte, so a multibyte PadChar is only possible in box mode,
+		// where cells are padded without it.
+		if !opts.Box && opts.PadChar >= utf8.RuneSelf {
+			return fmt.Errorf("ftable: multibyte padding character %q requires Box", opts.PadChar)
+		}
+	case "markdown", "html", "latex", "rst", "org", "adoc", "csv", "tsv", "nul":
+	case "json", "jsonl":
+		if !opts.Header {
+			return fmt.Errorf("ftable: %s format requires Header", opts.Format)
+		}
+	case "sql":
+		if !opts.Header {
+			return fmt.Errorf("ftable: %s format requires Header", opts.Format)
+		}
+		if opts.SQLTable == "" {
+			return fmt.Errorf("ftable: %s format requires SQLTable", opts.Format)
+		}
+	default:
+		return fmt.Errorf("ftable: unrecognized format %q", opts.Format)
 	}
+	return nil
+}
 
-	maxLen++
+// aligns reports whether opts set column alignments that override the tabwriter's AlignRight flag.
+func (opts *Options) aligns() bool {
+	return opts.Aligns != nil || opts.AutoNum
+}
 
-	var sepLine []byte
-	if rowlines {
-		sepLine = []byte(fmt.Sprintf("├─%s┤\n", applySep(bytes.Repeat([]byte("─"), maxLen), '┼', true)))
+// aligned reports whether opts require cells to be aligned per column, rather than uniformly by the tabwriter.
+// The tabwriter would right-align a kept indent along with the rest of its cell.
+func (opts *Options) aligned() bool {
+	return opts.aligns() || opts.Number || opts.Checks != nil || (opts.KeepIndent && opts.Flags&tabwriter.AlignRight != 0)
+}
+
+// indentCol returns the 0-based index of the output column whose indents are kept, or -1 if KeepIndent is not
+// set. It is the first column after any added by Number.
+func (opts *Options) indentCol() int {
+	switch {
+	case !opts.KeepIndent:
+		return -1
+	case opts.Number:
+		return 1
 	}
+	return 0
+}
 
-	for n, line := range lines {
-		if n == 0 {
-			if header {
-				line = applySep(line, '┃', false)
-				if rl := len(bytes.Runes(line)); rl < maxLen {
-					line = append(line, bytes.Repeat([]byte{' '}, maxLen-rl)...)
-				}
+// colored reports whether opts color any rows of text and box output.
+func (opts *Options) colored() bool {
+	return (opts.Header && opts.HeaderColor != "") || opts.Zebra != [2]string{}
+}
 
-				sep := bytes.Repeat([]byte("━"), maxLen)
-				fmt.Printf("┏━%s┓\n", applySep(sep, '┳', true))
-				fmt.Printf("┃ %s┃\n", applySep(line, '┃', false))
-				fmt.Printf("┡━%s┩\n", applySep(sep, '╇', true))
-			} else {
-				line = applySep(line, '│', false)
-				if rl := len(bytes.Runes(line)); rl < maxLen {
-					line = append(line, bytes.Repeat([]byte{' '}, maxLen-rl)...)
-				}
+// rowColor returns the SGR parameters with which to color row n of a table of nrows rows, or an empty string if
+// it is not colored.
+func (opts *Options) rowColor(n, nrows int) string {
+	switch {
+	case opts.Header && n == 0:
+		return opts.HeaderColor
+	case opts.Footer && n == nrows-1:
+		return ""
+	case opts.dataIndex != nil:
+		if n = opts.dataIndex[n]; n < 0 {
+			return ""
+		}
+	case opts.Header:
+		n--
+	}
+	return opts.Zebra[n%2]
+}
 
-				sep := bytes.Repeat([]byte("─"), maxLen)
-				fmt.Printf("┌─%s┐\n", applySep(sep, '┬', true))
-				fmt.Printf("│ %s│\n", line)
-			}
-		} else {
-			line = applySep(line, '│', false)
-			if rl := len(bytes.Runes(line)); rl < maxLen {
-				line = append(line, bytes.Repeat([]byte{' '}, maxLen-rl)...)
-			}
+// columnAlignments returns the alignment of each column of rows. Alignments set by Aligns take precedence over
+// those detected by AutoNum, which ignores the header and footer rows, if any. The row number column added by
+// Number is always right-aligned and is not counted by Aligns. Other columns are left-aligned, unless the
+// tabwriter's AlignRight flag is set.
+func (opts *Options) columnAlignments(rows [][]string) []Alignment {
+	fallback := AlignLeft
+	if opts.Flags&tabwriter.AlignRight != 0 {
+		fallback = AlignRight
+	}
 
-			if rowlines && ((header && n > 1) || !header) {
-				os.Stdout.Write(sepLine)
+	var aligns []Alignment
+	if opts.Number {
+		aligns = append(aligns, AlignRight)
+		numbered := rows
+		rows = make([][]string, len(numbered))
+		for n, row := range numbered {
+			if len(row) > 0 {
+				rows[n] = row[1:]
 			}
-			fmt.Printf("│ %s│\n", line)
 		}
+	}
+
+	var numeric []bool
+	if opts.AutoNum {
+		_, data, _ := opts.sections(rows)
+		numeric = numericColumns(data, opts.Grouping, opts.Empty)
+	}
+
+	checked := map[int]bool{}
+	for _, col := range opts.Checks {
+		for _, i := range opts.outputColumns(col) {
+			checked[i-len(aligns)] = true
+		}
+	}
 
+	for i := range columnWidths(rows) {
+		switch {
+		case i < len(opts.Aligns):
+			aligns = append(aligns, opts.Aligns[i])
+		case checked[i]:
+			aligns = append(aligns, AlignCenter)
+		case i < len(numeric) && numeric[i]:
+			aligns = append(aligns, AlignRight)
+		default:
+			aligns = append(aligns, fallback)
+		}
 	}
-	fmt.Printf("└━%s┘\n", applySep(bytes.Repeat([]byte("━"), maxLen), '┴', true))
+	return aligns
+}
+
+// dataFormat reports whether opts select a format of records for other programs to read, rather than a table for
+// people to: json, jsonl, csv, tsv, nul, or sql.
+func (opts *Options) dataFormat() bool {
+	switch opts.Format {
+	case "json", "jsonl", "csv", "tsv", "nul", "sql":
+		return true
+	}
+	return false
+}
+
+// indenter is an io.Writer that begins every line written through it with indent.
+type indenter struct {
+	w      io.Writer
+	indent string
+	// mid is set if the last write ended partway through a line.
+	mid bool
+}
 
+func (in *indenter) Write(p []byte) (int, error) {
+	var buf bytes.Buffer
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if !in.mid {
+			buf.WriteString(in.indent)
+		}
+		buf.Write(line)
+		in.mid = line[len(line)-1] != '\n'
+	}
+	if _, err := in.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// errWriter is an io.Writer that stops writing after the first error, which it keeps.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.w.Write(p)
+	ew.err = err
+	return n, err
 }
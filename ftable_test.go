@@ -0,0 +1,375 @@
+package ftable
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata with the output of the tests")
+
+// checkGolden fails t unless got matches the golden file testdata/name, which is first rewritten with got if
+// -update is set.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output does not match %s:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// benchInput returns tab-separated input of nrows rows of ncols cells each, of varying widths.
+func benchInput(nrows, ncols int) string {
+	var sb strings.Builder
+	for r := 0; r < nrows; r++ {
+		for c := 0; c < ncols; c++ {
+			if c > 0 {
+				sb.WriteByte('\t')
+			}
+			fmt.Fprintf(&sb, "r%dc%d%s", r, c, strings.Repeat("x", (r+c)%7))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func BenchmarkRender(b *testing.B) {
+	input := benchInput(10000, 8)
+	benchmarks := []struct {
+		name string
+		opts Options
+	}{
+		{"text", Options{Padding: 1}},
+		{"box", Options{Box: true, Header: true}},
+		{"box-rowlines", Options{Box: true, Header: true, RowLines: true}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			t := &Table{Options: bm.opts}
+			if _, err := t.ReadFrom(strings.NewReader(input)); err != nil {
+				b.Fatal(err)
+			}
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := t.Render(io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	tests := []struct {
+		name, in string
+	}{
+		{"empty", ""},
+		{"newline", "\n"},
+		{"crlf", "\r\n"},
+		{"blank lines", "\n\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tbl := &Table{Options: Options{Box: true, Header: true}}
+			if _, err := tbl.ReadFrom(strings.NewReader(tt.in)); err != nil {
+				t.Fatal(err)
+			}
+			var sb strings.Builder
+			if err := tbl.Render(&sb); err != ErrEmpty {
+				t.Errorf("Render() = %v, want ErrEmpty", err)
+			}
+			if sb.Len() > 0 {
+				t.Errorf("Render() wrote %q, want nothing", sb.String())
+			}
+		})
+	}
+}
+
+func TestRenderString(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		opts    Options
+		want    string
+		wantErr bool
+	}{
+		{name: "text", in: "a\tbb\nccc\td\n", opts: Options{Padding: 1}, want: "a   bb\nccc d\n"},
+		{name: "box", in: "a\tb\n", opts: Options{Box: true}, want: "┌───┬───┐\n│ a │ b │\n└───┴───┘\n"},
+		{name: "empty box", in: "", opts: Options{Box: true}, wantErr: true},
+		{name: "invalid format", in: "a\n", opts: Options{Format: "yaml"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(tt.in, tt.opts)
+			switch {
+			case tt.wantErr && err == nil:
+				t.Errorf("RenderString() = %q, want an error", got)
+			case !tt.wantErr && err != nil:
+				t.Errorf("RenderString() = %v", err)
+			case got != tt.want:
+				t.Errorf("RenderString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"defaults", Options{}, false},
+		{"sql", Options{Format: "sql", Header: true, SQLTable: "t"}, false},
+		{"format", Options{Format: "yaml"}, true},
+		{"style", Options{Style: "wavy"}, true},
+		{"title position", Options{TitlePos: "left"}, true},
+		{"vertical alignment", Options{VAlign: "sideways"}, true},
+		{"json without header", Options{Format: "json"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			err := opts.Validate()
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("Validate() = %v, want error: %v", err, tt.wantErr)
+			}
+			if opts.Format != tt.opts.Format || opts.Style != tt.opts.Style {
+				t.Errorf("Validate() changed opts to %+v", opts)
+			}
+		})
+	}
+}
+
+func TestZebra(t *testing.T) {
+	const in = "name\tqty\napple\t3\nwatermelon\t12\npear\t7\n"
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"text", Options{Padding: 1}},
+		{"box", Options{Box: true}},
+		{"box with header", Options{Box: true, Header: true}},
+		{"box with rowlines", Options{Box: true, Header: true, Footer: true, RowLines: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			opts := tt.opts
+			opts.Zebra = [2]string{"48;5;236", "2"}
+			got, err := RenderString(in, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == want {
+				t.Fatalf("zebra output is not colored:\n%s", got)
+			}
+			if stripANSI(got) != want {
+				t.Errorf("zebra output, without its colors:\n%s\nwant:\n%s", stripANSI(got), want)
+			}
+		})
+	}
+}
+
+func TestBoldHeader(t *testing.T) {
+	const in = "name\tqty\napple\t3\nwatermelon\t12\ntotal\t15\n"
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"text", Options{Header: true, Padding: 1}},
+		{"text aligned", Options{Header: true, Padding: 1, AutoNum: true}},
+		{"box", Options{Box: true, Header: true}},
+		{"box with footer", Options{Box: true, Header: true, Footer: true, RowLines: true}},
+		{"box with wide header", Options{Box: true, Header: true, MinWidth: 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			opts := tt.opts
+			opts.HeaderColor = "1"
+			got, err := RenderString(in, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(got, "\x1b[1m") {
+				t.Fatalf("header is not bold:\n%s", got)
+			}
+			if stripANSI(got) != want {
+				t.Errorf("bold output, without its escapes:\n%s\nwant:\n%s", stripANSI(got), want)
+			}
+			if opts.Box {
+				checkAligned(t, got)
+			}
+		})
+	}
+}
+
+func TestIndenter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{"lines", []string{"a\nb\n"}, "  a\n  b\n"},
+		{"split line", []string{"a", "b\nc", "\n"}, "  ab\n  c\n"},
+		{"split at newline", []string{"a\n", "b\n"}, "  a\n  b\n"},
+		{"empty lines", []string{"\n\n"}, "  \n  \n"},
+		{"empty write", []string{"", "a\n"}, "  a\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			w := &indenter{w: &sb, indent: "  "}
+			for _, s := range tt.writes {
+				if n, err := w.Write([]byte(s)); n != len(s) || err != nil {
+					t.Fatalf("Write(%q) = %d, %v", s, n, err)
+				}
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderIndent(t *testing.T) {
+	const in = "name\tqty\napple\t3\npear\t7\vor so\ntotal\t10\n"
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"text", Options{Header: true, Padding: 1}},
+		{"box", Options{Box: true, Header: true, Footer: true}},
+		{"box with row lines", Options{Box: true, Header: true, RowLines: true}},
+		{"box with title", Options{Box: true, Header: true, Title: "Fruit"}},
+		{"box with inline title", Options{Box: true, Title: "Fruit", TitleInline: true}},
+		{"frameless box", Options{Box: true, Header: true, NoOuter: true}},
+		{"markdown", Options{Format: "markdown", Header: true}},
+		{"rst", Options{Format: "rst", Header: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.opts.Indent = 4
+			got, err := RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.SplitAfter(got, "\n")
+			for n, line := range lines[:len(lines)-1] {
+				if !strings.HasPrefix(line, "    ") {
+					t.Errorf("line %d, %q, is not indented", n+1, line)
+				}
+				lines[n] = line[4:]
+			}
+			if unindented := strings.Join(lines, ""); unindented != want {
+				t.Errorf("without its indent, got:\n%s\nwant:\n%s", unindented, want)
+			}
+		})
+	}
+
+	// Records for other programs are never indented.
+	if got := renderTSV(t, "a\tb\n", Options{Indent: 4}); got != "a\tb\n" {
+		t.Errorf("tsv indented: %q", got)
+	}
+}
+
+func TestRenderCenter(t *testing.T) {
+	const in = "a\tb\n"
+	box := "┌───┬───┐\n│ a │ b │\n└───┴───┘\n" // 9 wide
+	tests := []struct {
+		name   string
+		opts   Options
+		indent int
+	}{
+		{"centered", Options{Box: true, Center: true, Width: 29}, 10},
+		{"odd margin", Options{Box: true, Center: true, Width: 30}, 10},
+		{"with indent", Options{Box: true, Center: true, Width: 29, Indent: 4}, 12},
+		{"as wide", Options{Box: true, Center: true, Width: 9}, 0},
+		{"wider", Options{Box: true, Center: true, Width: 10}, 0},
+		{"no width", Options{Box: true, Center: true}, 0},
+		{"indent as wide", Options{Box: true, Center: true, Width: 13, Indent: 4}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pad := strings.Repeat(" ", tt.indent)
+			want := pad + strings.Replace(strings.TrimSuffix(box, "\n"), "\n", "\n"+pad, -1) + "\n"
+			if got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+
+	// A title wider than the table is centered with it.
+	got, err := RenderString(in, Options{Box: true, Center: true, Width: 31, Title: "A long title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	for n, line := range lines {
+		if !strings.HasPrefix(line, strings.Repeat(" ", 9)) {
+			t.Errorf("line %d, %q, is not indented to center the title", n+1, line)
+		}
+	}
+}
+
+func TestFieldCounts(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts Options
+		want []int
+	}{
+		{"consistent", "a\tb\nc\td\n", Options{}, []int{2, 2}},
+		{"ragged", "a\tb\nc\nd\te\tf\n", Options{}, []int{2, 1, 3}},
+		{"delimiter", "a,b\tc\nd,e\n", Options{Delim: ","}, []int{2, 2}},
+		{"csv", "a,\"b,c\"\nd,\"e\nf\"\n", Options{CSV: true}, []int{2, 2}},
+		{"ragged csv", "a,b\nc\n", Options{CSV: true}, []int{2, 1}},
+		{"skipped lines", "# note\na\tb\n\nc\td\n", Options{Comment: "#", SkipBlank: true}, []int{2, 2}},
+		{"empty", "", Options{}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tbl := &Table{Options: tt.opts}
+			if _, err := tbl.ReadFrom(strings.NewReader(tt.in)); err != nil {
+				t.Fatal(err)
+			}
+			if got := tbl.FieldCounts(); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("FieldCounts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
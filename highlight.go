@@ -0,0 +1,154 @@
+package ftable
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Highlight selects the data cells of a column holding its greatest or least number, to be colored.
+type Highlight struct {
+	// Col is the 0-based index of the input column whose cells to highlight.
+	Col int
+	// Func is "max" or "min": whether to highlight the cells holding the greatest or the least number in the
+	// column. Every cell holding it is highlighted, and cells that are not numbers are never highlighted.
+	Func string
+}
+
+// ParseHighlights parses a comma-separated list of highlights, each a 1-based column number and "max" or "min",
+// separated by a colon, such as "2:max,3:min".
+func ParseHighlights(v string) ([]Highlight, error) {
+	var hs []Highlight
+	for _, field := range strings.Split(v, ",") {
+		col, fn, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid highlight %q: must be of the form column:max or column:min", field)
+		}
+
+		n, err := strconv.Atoi(col)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid column %q", col)
+		}
+
+		switch fn {
+		case "max", "min":
+		default:
+			return nil, fmt.Errorf("unrecognized highlight %q: must be max or min", fn)
+		}
+		hs = append(hs, Highlight{Col: n - 1, Func: fn})
+	}
+	return hs, nil
+}
+
+// highlightCells colors, with sgr, the cells of rows in column i holding the greatest number in it, or the least
+// if fn is "min". Any digit grouping characters group (if not zero) are ignored.
+func highlightCells(rows [][]string, i int, fn string, group rune, sgr string) {
+	value := func(row []string) (float64, bool) {
+		c := stripANSI(cell(row, i))
+		if group != 0 {
+			c = strings.Replace(c, string(group), "", -1)
+		}
+		x, err := strconv.ParseFloat(c, 64)
+		return x, err == nil
+	}
+
+	var best float64
+	found := false
+	for _, row := range rows {
+		x, ok := value(row)
+		if ok && (!found || (fn == "max" && x > best) || (fn == "min" && x < best)) {
+			best, found = x, true
+		}
+	}
+
+	for _, row := range rows {
+		if x, ok := value(row); ok && x == best {
+			row[i] = colorize(row[i], sgr)
+		}
+	}
+}
+
+// highlightMatches returns s with each match of re in its text colored with sgr, as grep --color does. Matches
+// never span escape sequences or line breaks, so the colors of s are kept, and adjacent matches are colored as
+// one. Empty matches are ignored.
+func highlightMatches(s string, re *regexp.Regexp, sgr string) string {
+	var sb strings.Builder
+	text := func(t string) {
+		for n, line := range strings.Split(t, lineBreak) {
+			if n > 0 {
+				sb.WriteString(lineBreak)
+			}
+
+			last, start, end := 0, -1, -1
+			flush := func() {
+				sb.WriteString(line[last:start])
+				sb.WriteString(colorize(line[start:end], sgr))
+				last = end
+			}
+			for _, m := range re.FindAllStringIndex(line, -1) {
+				switch {
+				case m[0] == m[1]:
+				case m[0] == end:
+					end = m[1]
+				default:
+					if end >= 0 {
+						flush()
+					}
+					start, end = m[0], m[1]
+				}
+			}
+			if end >= 0 {
+				flush()
+			}
+			sb.WriteString(line[last:])
+		}
+	}
+
+	pos := 0
+	for _, loc := range ansiEscape.FindAllStringIndex(s, -1) {
+		text(s[pos:loc[0]])
+		sb.WriteString(s[loc[0]:loc[1]])
+		pos = loc[1]
+	}
+	text(s[pos:])
+	return sb.String()
+}
+
+// outputColumns returns the 0-based indices of the output columns holding the input column col, after Cols
+// selects columns, Exclude drops them, and Number adds its own.
+func (opts *Options) outputColumns(col int) []int {
+	cols := opts.selectedColumns(col)
+	if opts.Number {
+		for n := range cols {
+			cols[n]++
+		}
+	}
+	return cols
+}
+
+// selectedColumns returns the 0-based indices of the columns holding the input column col once Cols selects
+// columns and Exclude drops them, before Number adds its own.
+func (opts *Options) selectedColumns(col int) []int {
+	if hasColumn(opts.Exclude, col) {
+		return nil
+	}
+
+	var cols []int
+	if opts.Cols == nil {
+		i := col
+		for n, c := range opts.Exclude {
+			// Exclude may name a column more than once, as "1,1-2" does.
+			if c < col && !hasColumn(opts.Exclude[:n], c) {
+				i--
+			}
+		}
+		cols = []int{i}
+	}
+	for i, c := range excludeColumns(opts.Cols, opts.Exclude) {
+		if c == col {
+			cols = append(cols, i)
+		}
+	}
+	return cols
+}
@@ -0,0 +1,215 @@
+package ftable
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseHighlights(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []Highlight
+		wantErr bool
+	}{
+		{"2:max", []Highlight{{Col: 1, Func: "max"}}, false},
+		{"2:max,3:min", []Highlight{{Col: 1, Func: "max"}, {Col: 2, Func: "min"}}, false},
+		{"2", nil, true},
+		{"0:max", nil, true},
+		{"x:max", nil, true},
+		{"2:avg", nil, true},
+		{"2:MAX", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseHighlights(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseHighlights(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseHighlights(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+// highlighted returns the cells of rows colored with sgr, as "row:column" pairs.
+func highlighted(rows [][]string, sgr string) []string {
+	var cells []string
+	for n, row := range rows {
+		for i, c := range row {
+			if strings.Contains(c, "\x1b["+sgr+"m") {
+				cells = append(cells, fmt.Sprintf("%d:%d", n, i))
+			}
+		}
+	}
+	return cells
+}
+
+func TestHighlightCells(t *testing.T) {
+	tests := []struct {
+		name  string
+		col   []string
+		fn    string
+		group rune
+		want  []string
+	}{
+		{"max", []string{"3", "10", "7"}, "max", 0, []string{"1:1"}},
+		{"min", []string{"3", "10", "7"}, "min", 0, []string{"0:1"}},
+		{"ties", []string{"10", "3", "10.0"}, "max", 0, []string{"0:1", "2:1"}},
+		{"negative", []string{"-3", "-10", "0"}, "min", 0, []string{"1:1"}},
+		{"not numbers", []string{"n/a", "5", "", "9x"}, "max", 0, []string{"1:1"}},
+		{"no numbers", []string{"a", "b"}, "max", 0, nil},
+		{"grouped", []string{"1,000", "999", "1,000,000"}, "max", ',', []string{"2:1"}},
+		{"grouped ignored", []string{"1,000", "999"}, "max", 0, []string{"1:1"}},
+		{"colored", []string{"\x1b[32m12\x1b[0m", "11"}, "max", 0, []string{"0:1"}},
+		{"short row", []string{"1", "", "2"}, "max", 0, []string{"2:1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := make([][]string, len(tt.col))
+			for n, c := range tt.col {
+				rows[n] = []string{"label", c}
+			}
+			highlightCells(rows, 1, tt.fn, tt.group, "7")
+			if got := highlighted(rows, "7"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("highlighted %v, want %v", got, tt.want)
+			}
+			for n, row := range rows {
+				if stripANSI(row[1]) != stripANSI(tt.col[n]) {
+					t.Errorf("row %d changed from %q to %q", n, tt.col[n], row[1])
+				}
+			}
+		})
+	}
+}
+
+func TestRenderHighlights(t *testing.T) {
+	const in = "name\tqty\tprice\napple\t3\t1.25\npear\t12\t0.5\nfig\t12\t2\ntotal\t27\t3.75\n"
+	opts := Options{Box: true, Header: true, Footer: true, Cols: []int{0, 2, 1},
+		Highlights: []Highlight{{Col: 1, Func: "max"}, {Col: 2, Func: "min"}}, HighlightColor: "7"}
+	got, err := RenderString(in, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain := opts
+	plain.Highlights = nil
+	want, err := RenderString(in, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stripANSI(got) != want {
+		t.Errorf("without its escapes, got:\n%s\nwant:\n%s", stripANSI(got), want)
+	}
+	checkAligned(t, got)
+
+	// Only the greatest quantities and the least price are highlighted, never the header or the footer.
+	var cells []string
+	for _, line := range strings.Split(got, "\n") {
+		for _, c := range strings.Split(line, "│") {
+			if strings.Contains(c, "\x1b[7m") {
+				cells = append(cells, strings.TrimSpace(stripANSI(c)))
+			}
+		}
+	}
+	if want := []string{"0.5", "12", "12"}; !reflect.DeepEqual(cells, want) {
+		t.Errorf("highlighted cells %q, want %q", cells, want)
+	}
+}
+
+func TestRenderHighlightsExcludedTwice(t *testing.T) {
+	opts := Options{Exclude: []int{0, 0, 1}, Highlights: []Highlight{{Col: 2, Func: "max"}}, HighlightColor: "7"}
+	got, err := RenderString("1\t2\t3\n4\t5\t6\n", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "3\n\x1b[7m6\x1b[0m\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	const on, off = "\x1b[7m", "\x1b[0m"
+	tests := []struct {
+		name string
+		s    string
+		re   string
+		want string
+	}{
+		{"one", "apple pie", "pie", "apple " + on + "pie" + off},
+		{"several", "banana", "an", "b" + on + "anan" + off + "a"},
+		{"apart", "a-b-c", "[ac]", on + "a" + off + "-b-" + on + "c" + off},
+		{"none", "apple", "x", "apple"},
+		{"empty matches", "abc", "x*", "abc"},
+		{"whole", "abc", ".*", on + "abc" + off},
+		{"line breaks", "ab" + lineBreak + "ba", "[ab]+", on + "ab" + off + lineBreak + on + "ba" + off},
+		{"colored", "\x1b[32mgreen\x1b[0m tea", "e+", "\x1b[32mgr" + on + "ee" + off + "n\x1b[0m t" + on + "e" + off + "a"},
+		{"across escapes", "ab\x1b[1mcd", "bc", "ab\x1b[1mcd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := highlightMatches(tt.s, regexp.MustCompile(tt.re), "7")
+			if got != tt.want {
+				t.Errorf("highlightMatches(%q, %q) = %q, want %q", tt.s, tt.re, got, tt.want)
+			}
+			if stripANSI(got) != stripANSI(tt.s) {
+				t.Errorf("text changed from %q to %q", stripANSI(tt.s), stripANSI(got))
+			}
+		})
+	}
+}
+
+func TestRenderMatch(t *testing.T) {
+	const in = "name\tnote\napple\tred apple\npear\tgreen\npineapple\tplain\n"
+	opts := Options{Box: true, Header: true, Match: regexp.MustCompile("ap+le|pl"), MatchColor: "7"}
+	got, err := RenderString(in, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain := opts
+	plain.Match = nil
+	want, err := RenderString(in, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stripANSI(got) != want {
+		t.Errorf("without its escapes, got:\n%s\nwant:\n%s", stripANSI(got), want)
+	}
+	checkAligned(t, got)
+
+	var matches []string
+	for _, m := range regexp.MustCompile("\x1b\\[7m(.*?)\x1b\\[0m").FindAllStringSubmatch(got, -1) {
+		matches = append(matches, m[1])
+	}
+	if want := []string{"apple", "apple", "apple", "pl"}; !reflect.DeepEqual(matches, want) {
+		t.Errorf("matches %q, want %q", matches, want)
+	}
+}
+
+func TestDataFormatsUncolored(t *testing.T) {
+	const in = "name\tqty\napple\t3\npear\t7\n"
+	for _, format := range []string{"json", "jsonl", "csv", "tsv", "nul", "sql"} {
+		t.Run(format, func(t *testing.T) {
+			plain := Options{Format: format, Header: true, SQLTable: "fruit"}
+			want, err := RenderString(in, plain)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			opts := plain
+			opts.Match = regexp.MustCompile("p+")
+			opts.Highlights = []Highlight{{Col: 1, Func: "max"}}
+			opts.Link = 1
+			got, err := RenderString(in, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package ftable
+
+import (
+	"html"
+	"io"
+	"strings"
+)
+
+// writeHTML writes rows to w as an HTML table. If header is set, the first row is written in the table's
+// <thead> as <th> cells. Short rows are padded with empty cells to the width of the longest row, and line breaks
+// within cells are written as <br> tags.
+func writeHTML(w io.Writer, rows [][]string, header bool) {
+	ncols := len(columnWidths(rows))
+
+	var b strings.Builder
+	writeRow := func(row []string, tag string) {
+		b.WriteString("<tr>")
+		for i := 0; i < ncols; i++ {
+			var cell string
+			if i < len(row) {
+				cell = row[i]
+			}
+			b.WriteString("<" + tag + ">")
+			b.WriteString(strings.Replace(html.EscapeString(cell), lineBreak, "<br>", -1))
+			b.WriteString("</" + tag + ">")
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n")
+	if header && len(rows) > 0 {
+		b.WriteString("<thead>\n")
+		writeRow(rows[0], "th")
+		b.WriteString("</thead>\n")
+		rows = rows[1:]
+	}
+	b.WriteString("<tbody>\n")
+	for _, row := range rows {
+		writeRow(row, "td")
+	}
+	b.WriteString("</tbody>\n</table>\n")
+
+	io.WriteString(w, b.String())
+}
@@ -0,0 +1,422 @@
+package ftable
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// retabber rewrites a single line of input, without its line ending, so that its columns are separated by tabs.
+type retabber func(line []byte) []byte
+
+// retabber returns the retabber described by opts, or nil if input columns are already tab-separated. Tabs within
+// columns are expanded to spaces, so that they do not separate columns of their own.
+func (opts *Options) retabber() retabber {
+	tab := []byte("\t")
+	tabWidth := opts.TabWidth
+	switch {
+	case opts.DelimRE != nil:
+		re := opts.DelimRE
+		return func(line []byte) []byte {
+			fields := re.Split(string(line), -1)
+			// A match at the start of a line (e.g., leading whitespace for -D '\s+') does not produce an empty
+			// first column.
+			if len(fields) > 1 && fields[0] == "" {
+				fields = fields[1:]
+			}
+			for i, field := range fields {
+				fields[i] = expandTabs(field, tabWidth)
+			}
+			return []byte(strings.Join(fields, "\t"))
+		}
+	case opts.Delim != "":
+		sep := []byte(opts.Delim)
+		return func(line []byte) []byte {
+			fields := bytes.Split(line, sep)
+			for i, field := range fields {
+				if bytes.IndexByte(field, '\t') >= 0 {
+					fields[i] = []byte(expandTabs(string(field), tabWidth))
+				}
+			}
+			return bytes.Join(fields, tab)
+		}
+	}
+	return nil
+}
+
+// expandTabs returns s with each tab replaced by spaces up to the next tab stop, every width columns from the
+// start of s (or of the line, in a cell with line breaks). A width less than 1 is taken as 8.
+func expandTabs(s string, width int) string {
+	if strings.IndexByte(s, '\t') < 0 {
+		return s
+	}
+	if width < 1 {
+		width = 8
+	}
+
+	var sb strings.Builder
+	col := 0
+	for _, r := range s {
+		switch r {
+		case '\t':
+			n := width - col%width
+			sb.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		case '\v', '\n':
+			col = 0
+		default:
+			col += runeWidth(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// copyColumns copies r to w, normalizing CRLF and lone CR line endings to LF. If retab is not nil, each line of
+// r is passed through it first so that its columns are seen by the tabwriter.
+func copyColumns(w io.Writer, r io.Reader, retab retabber) error {
+	cr, lf := []byte("\r"), []byte("\n")
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			var eol []byte
+			if bytes.HasSuffix(line, lf) {
+				line, eol = bytes.TrimSuffix(line[:len(line)-1], cr), lf
+			}
+
+			lines := bytes.Split(line, cr)
+			if retab != nil {
+				for i, l := range lines {
+					lines[i] = retab(l)
+				}
+			}
+
+			line = append(bytes.Join(lines, lf), eol...)
+			if _, werr := w.Write(line); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// copyCSV parses r as CSV records separated by comma and writes them to w as tab-separated lines. Newlines
+// embedded in quoted fields are kept as line breaks within their cells, since each record must occupy a single
+// line, and tabs are expanded to spaces with stops every tabWidth columns.
+func copyCSV(w io.Writer, r io.Reader, comma rune, tabWidth int) error {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+
+	flatten := strings.NewReplacer("\r\n", lineBreak, "\n", lineBreak, "\r", lineBreak)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		for i, field := range record {
+			record[i] = expandTabs(flatten.Replace(field), tabWidth)
+		}
+		if _, err = io.WriteString(w, strings.Join(record, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+}
+
+// copyMarkdown parses r as a GitHub-flavored Markdown table and writes its rows to w as tab-separated lines. Cells
+// are trimmed of surrounding spaces, escaped pipes are unescaped, and <br> tags become line breaks, as written by
+// the markdown format. The delimiter row is dropped, along with the header row above it if it is empty, as it is
+// when written without a header. Lines without any pipes, such as text around the table, are skipped.
+func copyMarkdown(w io.Writer, r io.Reader, tabWidth int) error {
+	var rows [][]string
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if row := markdownRow(line); row != nil {
+			switch {
+			case !markdownDelimiter(row):
+				for i, cell := range row {
+					row[i] = expandTabs(cell, tabWidth)
+				}
+				rows = append(rows, row)
+			case len(rows) == 1 && strings.Join(rows[0], "") == "":
+				rows = rows[:0]
+			}
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		if _, err := io.WriteString(w, strings.Join(row, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownRow returns the cells of line, a row of a Markdown table, or nil if it has no unescaped pipes. The pipes
+// at either end of the row are optional.
+func markdownRow(line string) []string {
+	line = strings.TrimSpace(line)
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case c == '|':
+			cells = append(cells, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(c)
+		}
+	}
+	if cells == nil {
+		return nil
+	}
+	cells = append(cells, cell.String())
+
+	if strings.HasPrefix(line, "|") {
+		cells = cells[1:]
+	}
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") && len(cells) > 0 {
+		cells = cells[:len(cells)-1]
+	}
+
+	br := strings.NewReplacer("<br>", lineBreak, "<br/>", lineBreak, "<br />", lineBreak)
+	for i, cell := range cells {
+		cells[i] = br.Replace(strings.TrimSpace(cell))
+	}
+	return cells
+}
+
+// markdownDelimiter reports whether row is the delimiter row of a Markdown table, below its header, of hyphens
+// optionally bracketed by colons marking column alignments.
+func markdownDelimiter(row []string) bool {
+	for _, cell := range row {
+		cell = strings.TrimSuffix(strings.TrimPrefix(cell, ":"), ":")
+		if cell == "" || strings.Trim(cell, "-") != "" {
+			return false
+		}
+	}
+	return len(row) > 0
+}
+
+// skipLine reports whether line, including its line ending, is skipped by opts: if it is blank, with SkipBlank,
+// or begins with Comment.
+func (opts *Options) skipLine(line []byte) bool {
+	if opts.SkipBlank && len(bytes.Trim(line, " \r\n")) == 0 {
+		return true
+	}
+	return opts.Comment != "" && bytes.HasPrefix(line, []byte(opts.Comment))
+}
+
+// lineSkipper is an io.Reader of the lines of r for which skip returns false. If quoted is set, lines are read as
+// CSV, and a line beginning within a double-quoted field is never skipped, since it continues the record before
+// it.
+type lineSkipper struct {
+	r        *bufio.Reader
+	skip     func(line []byte) bool
+	quoted   bool
+	inQuotes bool
+	line     []byte
+	err      error
+}
+
+func (s *lineSkipper) Read(p []byte) (int, error) {
+	for len(s.line) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+
+		var line []byte
+		line, s.err = s.r.ReadBytes('\n')
+		if !s.inQuotes && s.skip(line) {
+			continue
+		}
+		if s.quoted && bytes.Count(line, []byte(`"`))%2 == 1 {
+			s.inQuotes = !s.inQuotes
+		}
+		s.line = line
+	}
+
+	n := copy(p, s.line)
+	s.line = s.line[n:]
+	return n, nil
+}
+
+// DelimAuto is the Delim that has the delimiter of input detected from its first lines, with DetectDelim.
+const DelimAuto = "auto"
+
+// delimCandidates are the delimiters considered by DetectDelim, in order of preference.
+var delimCandidates = []string{"\t", ",", ";", "|"}
+
+// detectLines is the number of lines sampled by DetectDelim.
+const detectLines = 10
+
+// DetectDelim returns the delimiter among tab, comma, semicolon, and pipe that separates the first lines of
+// sample into the same number of columns, more than one, on every line. If more than one does, the one yielding
+// the most columns is returned; if none does, DetectDelim returns a tab. Empty lines are skipped, and the last
+// line is ignored if it is incomplete, unless it is the only one. If quoted is set, sample is read as CSV:
+// delimiters and line endings within double-quoted fields are not counted.
+func DetectDelim(sample []byte, quoted bool) string {
+	var counts [][]int // The number of each candidate on each line.
+	count := make([]int, len(delimCandidates))
+	blank, inQuotes := true, false
+	for _, c := range sample {
+		if len(counts) == detectLines {
+			break
+		}
+		switch {
+		case quoted && c == '"':
+			inQuotes = !inQuotes
+		case c == '\n' && !inQuotes:
+			if !blank {
+				counts = append(counts, count)
+			}
+			count = make([]int, len(delimCandidates))
+			blank = true
+			continue
+		case inQuotes:
+		default:
+			for n, delim := range delimCandidates {
+				if c == delim[0] {
+					count[n]++
+				}
+			}
+		}
+		if c != '\r' {
+			blank = false
+		}
+	}
+	if len(counts) == 0 && !blank {
+		counts = append(counts, count)
+	}
+
+	best, bestCols := "\t", 1
+candidates:
+	for n, delim := range delimCandidates {
+		cols := 0
+		for _, count := range counts {
+			if count[n] == 0 || (cols != 0 && count[n]+1 != cols) {
+				continue candidates
+			}
+			cols = count[n] + 1
+		}
+		if cols > bestCols {
+			best, bestCols = delim, cols
+		}
+	}
+	return best
+}
+
+// detectSample is the most input read ahead to detect its delimiter.
+const detectSample = 64 << 10
+
+// detectDelim replaces a Delim of DelimAuto with the delimiter detected from the start of r, returning a reader
+// of all of r.
+func (opts *Options) detectDelim(r io.Reader) io.Reader {
+	br := bufio.NewReaderSize(r, detectSample)
+	sample, _ := br.Peek(detectSample)
+	opts.Delim = DetectDelim(sample, opts.CSV)
+	return br
+}
+
+// gzipMagic are the bytes with which gzip-compressed data begins.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decompress returns a reader of r, decompressed if it begins as gzip-compressed data does.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(len(gzipMagic)); !bytes.Equal(magic, gzipMagic) {
+		return br, nil
+	}
+	return gzip.NewReader(br)
+}
+
+// splitRecords returns a reader of all of r with each of its records, separated by sep, on a line of its own. Line
+// endings within records become line breaks within their cells, save for one ending the input, and a separator
+// ending the last record does not begin an empty one.
+func splitRecords(r io.Reader, sep string) (io.Reader, error) {
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+
+	data, end := buf.Bytes(), []byte(sep)
+	if !bytes.HasSuffix(data, end) && bytes.HasSuffix(data, []byte("\n")) {
+		data = bytes.TrimSuffix(data[:len(data)-1], []byte("\r"))
+	}
+	data = bytes.TrimSuffix(data, end)
+	if len(data) == 0 {
+		return bytes.NewReader(nil), nil
+	}
+
+	// Split before flattening line endings, so that a separator beginning with one still separates records.
+	flatten := strings.NewReplacer("\r\n", lineBreak, "\n", lineBreak, "\r", lineBreak)
+	var out bytes.Buffer
+	for _, record := range bytes.Split(data, end) {
+		out.WriteString(flatten.Replace(string(record)))
+		out.WriteByte('\n')
+	}
+	return &out, nil
+}
+
+// copyInput copies r to w, converting its columns to tab-separated form as described by opts. Gzip-compressed
+// input is decompressed first, and then split into records by any RecordSep. A Delim of DelimAuto is replaced by
+// the delimiter detected from r, which is then used for any later input.
+func (opts *Options) copyInput(w io.Writer, r io.Reader) error {
+	r, err := decompress(r)
+	if err != nil {
+		return err
+	}
+	if opts.RecordSep != "" && opts.RecordSep != "\n" {
+		if r, err = splitRecords(r, opts.RecordSep); err != nil {
+			return err
+		}
+	}
+	if opts.StripColor {
+		w = ansiStripper{w}
+	}
+	if opts.SkipBlank || opts.Comment != "" {
+		r = &lineSkipper{r: bufio.NewReader(r), skip: opts.skipLine, quoted: opts.CSV}
+	}
+	if opts.Delim == DelimAuto && opts.DelimRE == nil {
+		r = opts.detectDelim(r)
+		if opts.CSV && opts.Comma == 0 {
+			opts.Comma, _ = utf8.DecodeRuneInString(opts.Delim)
+		}
+	}
+	if opts.Fixed != nil || opts.FixedAuto {
+		return copyFixed(w, r, opts.Fixed, opts.TabWidth)
+	}
+	if opts.From == "markdown" {
+		return copyMarkdown(w, r, opts.TabWidth)
+	}
+	if opts.CSV {
+		comma := opts.Comma
+		if comma == 0 {
+			comma = ','
+		}
+		return copyCSV(w, r, comma, opts.TabWidth)
+	}
+	return copyColumns(w, r, opts.retabber())
+}
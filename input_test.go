@@ -0,0 +1,331 @@
+package ftable
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+)
+
+func TestCopyColumnsLineEndings(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"lf", "a\tb\nc\td\n", "a\tb\nc\td\n"},
+		{"crlf", "a\tb\r\nc\td\r\n", "a\tb\nc\td\n"},
+		{"cr", "a\tb\rc\td\r", "a\tb\nc\td\n"},
+		{"crlf without final newline", "a\tb\r\nc\td", "a\tb\nc\td"},
+		{"mixed", "a\r\nb\nc\rd\n", "a\nb\nc\nd\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := copyColumns(&sb, strings.NewReader(tt.in), nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("copyColumns(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderCRLF(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"box", Options{Box: true, Header: true}},
+		{"csv box", Options{Box: true, CSV: true, Comma: '\t'}},
+		{"text", Options{Padding: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := RenderString("id\tname\n1\twidget\n22\tgear\n", tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := RenderString("id\tname\r\n1\twidget\r\n22\tgear\r\n", tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("CRLF input rendered as:\n%s\nwant:\n%s", got, want)
+			}
+			if tt.opts.Box {
+				checkAligned(t, got)
+			}
+		})
+	}
+}
+
+func TestDetectDelim(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample string
+		quoted bool
+		want   string
+	}{
+		{"tsv", "name\tqty\napple\t3\npear\t7\n", false, "\t"},
+		{"csv", "name,qty,price\napple,3,1.25\npear,7,0.5\n", false, ","},
+		{"pipes", "name|qty\napple|3\npear|7\n", false, "|"},
+		{"semicolons", "name;qty\napple;3\n", false, ";"},
+		{"most columns", "a,b;c,d\ne,f;g,h\n", false, ","},
+		{"inconsistent", "a,b\nc,d,e\nf|g\n", false, "\t"},
+		{"single column", "a\nb\n", false, "\t"},
+		{"blank lines", "\na|b\n\nc|d\n\n", false, "|"},
+		{"crlf", "a,b\r\nc,d\r\n", false, ","},
+		{"incomplete last line", "a,b\nc,d\ne|f|g", false, ","},
+		{"one line", "a;b;c", false, ";"},
+		{"quoted csv", "name,note\napple,\"red, or green\"\npear,\"a\nb\"\n", true, ","},
+		{"quoted csv read unquoted", "name,note\napple,\"red, or green\"\n", false, "\t"},
+		{"empty", "", false, "\t"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectDelim([]byte(tt.sample), tt.quoted); got != tt.want {
+				t.Errorf("DetectDelim(%q, %v) = %q, want %q", tt.sample, tt.quoted, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderDelimAuto(t *testing.T) {
+	want := "a\tb\nc\td\n"
+	for _, in := range []string{"a\tb\nc\td\n", "a,b\nc,d\n", "a|b\nc|d\n"} {
+		if got := renderTSV(t, in, Options{Delim: DelimAuto}); got != want {
+			t.Errorf("%q rendered as %q, want %q", in, got, want)
+		}
+	}
+
+	tbl := &Table{Options: Options{Delim: DelimAuto}}
+	if _, err := tbl.ReadFrom(strings.NewReader("a|b\n")); err != nil {
+		t.Fatal(err)
+	}
+	if tbl.Delim != "|" {
+		t.Errorf("Delim = %q after reading, want the detected %q", tbl.Delim, "|")
+	}
+}
+
+func TestSkipLine(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		line string
+		want bool
+	}{
+		{"blank", Options{SkipBlank: true}, "\n", true},
+		{"spaces", Options{SkipBlank: true}, "  \r\n", true},
+		{"blank kept", Options{}, "\n", false},
+		{"last line", Options{SkipBlank: true}, "", true},
+		{"comment", Options{Comment: "#"}, "# note\n", true},
+		{"indented comment", Options{Comment: "#"}, " # note\n", false},
+		{"long comment", Options{Comment: "//"}, "//x\n", true},
+		{"comment kept", Options{SkipBlank: true}, "# note\n", false},
+		{"data", Options{SkipBlank: true, Comment: "#"}, "a\tb#c\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.skipLine([]byte(tt.line)); got != tt.want {
+				t.Errorf("skipLine(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSkipLines(t *testing.T) {
+	in := "# inventory\n\nname\tqty\n# fruit\napple\t3\n\n  \npear\t7\n# end\n"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"tsv", Options{SkipBlank: true, Comment: "#"}, "name\tqty\napple\t3\npear\t7\n"},
+		{"csv", Options{CSV: true, Comma: '\t', SkipBlank: true, Comment: "#"}, "name\tqty\napple\t3\npear\t7\n"},
+		{"comments only", Options{Comment: "#"}, "\nname\tqty\napple\t3\n\n\"  \"\npear\t7\n"},
+		{"header", Options{Header: true, SkipBlank: true, Comment: "#"}, "name\tqty\napple\t3\npear\t7\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderTSV(t, in, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// The header is the first line kept, not the first line of input.
+	got, err := RenderString(in, Options{Box: true, Header: true, SkipBlank: true, Comment: "#"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(got, "\n")
+	if len(lines) < 3 || !strings.Contains(lines[1], "name") || strings.HasPrefix(lines[2], "│") {
+		t.Errorf("header is not the first line kept:\n%s", got)
+	}
+	checkAligned(t, got)
+}
+
+func TestRenderQuotedComment(t *testing.T) {
+	in := "name,note\napple,\"first\n# not a comment\n\nstill the note\"\n# a comment\npear,x\n"
+	got := renderTSV(t, in, Options{CSV: true, SkipBlank: true, Comment: "#"})
+	want := "name\tnote\napple\t\"first\n# not a comment\n\nstill the note\"\npear\tx\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// gzipped returns s compressed with gzip.
+func gzipped(t *testing.T, s string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestRenderGzip(t *testing.T) {
+	const csv = "name,note\napple,\"red, green\"\npear,\"a\nb\"\n"
+	tests := []struct {
+		name string
+		in   string
+		opts Options
+		want string
+	}{
+		{"csv", gzipped(t, csv), Options{CSV: true}, "name\tnote\napple\tred, green\npear\t\"a\nb\"\n"},
+		{"tsv", gzipped(t, "a\tb\nc\td\n"), Options{}, "a\tb\nc\td\n"},
+		{"detected delimiter", gzipped(t, "a|b\nc|d\n"), Options{Delim: DelimAuto}, "a\tb\nc\td\n"},
+		{"uncompressed", csv, Options{CSV: true}, "name\tnote\napple\tred, green\npear\t\"a\nb\"\n"},
+		{"magic alone", "\x1f", Options{}, "\x1f\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderTSV(t, tt.in, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := RenderString(gzipped(t, csv)[:20], Options{CSV: true}); err == nil {
+		t.Error("no error for truncated gzip input")
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"no tabs", 8, "no tabs"},
+		{"a\tb", 8, "a       b"},
+		{"abcdefgh\tb", 8, "abcdefgh        b"},
+		{"a\tb\tc", 4, "a   b   c"},
+		{"\t", 0, "        "},
+		{"漢\tb", 4, "漢  b"},
+		{"ab\tc" + lineBreak + "d\te", 4, "ab  c" + lineBreak + "d   e"},
+	}
+	for _, tt := range tests {
+		if got := expandTabs(tt.s, tt.width); got != tt.want {
+			t.Errorf("expandTabs(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestRenderCellTabs(t *testing.T) {
+	const in = "name,note\napple,red\tand green\npear,\tindented\n"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			"delimited", Options{Delim: ",", TabWidth: 4},
+			"name  note\napple red and green\npear      indented\n",
+		},
+		{
+			"csv", Options{CSV: true, TabWidth: 4},
+			"name  note\napple red and green\npear      indented\n",
+		},
+		{
+			"box", Options{Box: true, Delim: ",", TabWidth: 4},
+			"┌───────┬───────────────┐\n" +
+				"│ name  │ note          │\n" +
+				"│ apple │ red and green │\n" +
+				"│ pear  │     indented  │\n" +
+				"└───────┴───────────────┘\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Padding = 1
+			got, err := RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if strings.Contains(got, "\t") {
+				t.Errorf("output holds a tab: %q", got)
+			}
+		})
+	}
+}
+
+func TestSplitRecords(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		sep  string
+		want string
+	}{
+		{"semicolons", "a\tb;c\td", ";", "a\tb\nc\td\n"},
+		{"trailing separator", "a;b;", ";", "a\nb\n"},
+		{"trailing newline", "a;b\n", ";", "a\nb\n"},
+		{"trailing crlf", "a;b\r\n", ";", "a\nb\n"},
+		{"separator and newline", "a;b;\n", ";", "a\nb\n"},
+		{"line breaks", "a\nb;c\r\nd;e\rf", ";", "a" + lineBreak + "b\nc" + lineBreak + "d\ne" + lineBreak + "f\n"},
+		{"empty records", "a;;b", ";", "a\n\nb\n"},
+		{"blank lines", "a\tb\nc\n\nd\te\n", "\n\n", "a\tb" + lineBreak + "c\nd\te\n"},
+		{"blank lines ended", "a\n\nb\n\n", "\n\n", "a\nb\n"},
+		{"long separator", "a--b--c", "--", "a\nb\nc\n"},
+		{"empty", "", ";", ""},
+		{"separator only", ";", ";", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := splitRecords(strings.NewReader(tt.in), tt.sep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got bytes.Buffer
+			if _, err := got.ReadFrom(r); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("splitRecords(%q, %q) = %q, want %q", tt.in, tt.sep, got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderRecordSep(t *testing.T) {
+	got, err := RenderString("name\tnote;apple\tred\nor green;pear\tx;", Options{Box: true, RecordSep: ";"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "┌───────┬──────────┐\n" +
+		"│ name  │ note     │\n" +
+		"│ apple │ red      │\n" +
+		"│       │ or green │\n" +
+		"│ pear  │ x        │\n" +
+		"└───────┴──────────┘\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
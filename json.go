@@ -0,0 +1,129 @@
+package ftable
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jsonObject encodes a row as a JSON object, mapping each of keys to the cell in the same column. Keys are
+// encoded in order, so objects mirror the column order of the table. Columns missing from a short row are
+// encoded as empty strings.
+type jsonObject struct {
+	keys, values []string
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := newJSONEncoder(&buf)
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		var value string
+		if i < len(o.values) {
+			value = o.values[i]
+		}
+
+		if err := enc.Encode(key); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1) // Encode's newline
+		buf.WriteByte(':')
+		if err := enc.Encode(value); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// newJSONEncoder returns a json.Encoder writing to w that leaves <, >, and & unescaped, since the output is not
+// meant for embedding in HTML.
+func newJSONEncoder(w io.Writer) *json.Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc
+}
+
+// jsonObjects returns the rows following the header row of rows as jsonObjects keyed by the header's cells. It
+// returns an error if any row has more cells than the header.
+func jsonObjects(rows [][]string) ([]jsonObject, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	keys := jsonStrings(rows[0])
+	objs := make([]jsonObject, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		if len(row) > len(keys) {
+			return nil, fmt.Errorf("row %d has %d fields, but the header has only %d", i+2, len(row), len(keys))
+		}
+		objs = append(objs, jsonObject{keys: keys, values: jsonStrings(row)})
+	}
+	return objs, nil
+}
+
+// jsonStrings returns the cells of row with their line breaks as newlines.
+func jsonStrings(row []string) []string {
+	strs := make([]string, len(row))
+	for i, cell := range row {
+		strs[i] = strings.Replace(cell, lineBreak, "\n", -1)
+	}
+	return strs
+}
+
+// writeJSON writes rows to w as a JSON array of objects, one per row after the header row, which provides the
+// objects' keys. Each object is written on its own line as it is encoded, with the commas between them leading
+// the lines after the first.
+func writeJSON(w io.Writer, rows [][]string) error {
+	objs, err := jsonObjects(rows)
+	if err != nil {
+		return err
+	}
+
+	if len(objs) == 0 {
+		_, err = io.WriteString(w, "[]\n")
+		return err
+	}
+
+	if _, err = io.WriteString(w, "[\n"); err != nil {
+		return err
+	}
+	if err = encodeJSON(w, objs, ","); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "]\n")
+	return err
+}
+
+// writeJSONLines writes rows to w as JSON Lines: the objects of writeJSON, each on a line of its own, without an
+// enclosing array.
+func writeJSONLines(w io.Writer, rows [][]string) error {
+	objs, err := jsonObjects(rows)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(w, objs, "")
+}
+
+// encodeJSON encodes each of objs to w on a line of its own, writing sep before each object after the first.
+func encodeJSON(w io.Writer, objs []jsonObject, sep string) error {
+	enc := newJSONEncoder(w)
+	for i, obj := range objs {
+		if i > 0 && sep != "" {
+			if _, err := io.WriteString(w, sep); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
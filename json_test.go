@@ -0,0 +1,112 @@
+package ftable
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONObject(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  jsonObject
+		want string
+	}{
+		{"ordered", jsonObject{keys: []string{"z", "a"}, values: []string{"1", "2"}}, `{"z":"1","a":"2"}`},
+		{"short row", jsonObject{keys: []string{"a", "b"}, values: []string{"1"}}, `{"a":"1","b":""}`},
+		{"escaped", jsonObject{keys: []string{`"q"`}, values: []string{"a\nb\t\"c\""}}, `{"\"q\"":"a\nb\t\"c\""}`},
+		{"empty", jsonObject{}, `{}`},
+		{"html", jsonObject{keys: []string{"<a>"}, values: []string{"x & y"}}, `{"<a>":"x & y"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := newJSONEncoder(&buf).Encode(tt.obj); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimSuffix(buf.String(), "\n"); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	in := "name\tnote\napple\tsay \"hi\"\npear\n"
+	got, err := RenderString(in, Options{Format: "json", Header: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[\n{\"name\":\"apple\",\"note\":\"say \\\"hi\\\"\"}\n,{\"name\":\"pear\",\"note\":\"\"}\n]\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	var objs []map[string]string
+	if err := json.Unmarshal([]byte(got), &objs); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+
+	if got, err := RenderString("name\n", Options{Format: "json", Header: true}); err != nil || got != "[]\n" {
+		t.Errorf("header only: got %q, %v, want %q", got, err, "[]\n")
+	}
+}
+
+func TestRenderJSONLines(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []map[string]string
+	}{
+		{"plain", "name\tqty\napple\t3\npear\t7\n", []map[string]string{
+			{"name": "apple", "qty": "3"},
+			{"name": "pear", "qty": "7"},
+		}},
+		{"escapes", "key\tvalue\nq\t\"quoted\" \\ back\nu\t漢字 \x01\nh\t<b> & </b>\n", []map[string]string{
+			{"key": "q", "value": `"quoted" \ back`},
+			{"key": "u", "value": "漢字 \x01"},
+			{"key": "h", "value": "<b> & </b>"},
+		}},
+		{"short rows", "a\tb\tc\n1\n\t2\n", []map[string]string{
+			{"a": "1", "b": "", "c": ""},
+			{"a": "", "b": "2", "c": ""},
+		}},
+		{"header only", "a\tb\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(tt.in, Options{Format: "jsonl", Header: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.SplitAfter(got, "\n")
+			if lines[len(lines)-1] != "" {
+				t.Fatalf("output does not end in a newline: %q", got)
+			}
+			lines = lines[:len(lines)-1]
+			if len(lines) != len(tt.want) {
+				t.Fatalf("got %d lines, want %d: %q", len(lines), len(tt.want), got)
+			}
+			for n, line := range lines {
+				var obj map[string]string
+				if err := json.Unmarshal([]byte(line), &obj); err != nil {
+					t.Errorf("line %d, %q, is not JSON: %v", n+1, line, err)
+					continue
+				}
+				if strings.Contains(line, `\u003c`) || strings.Contains(line, `\u0026`) {
+					t.Errorf("line %d, %q, escapes HTML characters", n+1, line)
+				}
+				if !reflect.DeepEqual(obj, tt.want[n]) {
+					t.Errorf("line %d = %v, want %v", n+1, obj, tt.want[n])
+				}
+			}
+		})
+	}
+
+	if _, err := RenderString("a\n1\t2\n", Options{Format: "jsonl", Header: true}); err == nil {
+		t.Error("no error for a row longer than the header")
+	}
+}
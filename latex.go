@@ -0,0 +1,55 @@
+package ftable
+
+import (
+	"io"
+	"strings"
+)
+
+// latexEscaper escapes the characters that are special in LaTeX text. Line breaks within cells, which a tabular
+// column of type l, r, or c cannot hold, are replaced with spaces.
+var latexEscaper = strings.NewReplacer(
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+	`\`, `\textbackslash{}`,
+	lineBreak, " ",
+)
+
+// writeLaTeX writes rows to w as a LaTeX tabular environment whose column spec is given by aligns. Rules are
+// drawn above and below the table and, if header or footer is set, below the first row or above the last row,
+// respectively.
+func writeLaTeX(w io.Writer, rows [][]string, aligns []Alignment, header, footer bool) {
+	ncols := len(columnWidths(rows))
+	if ncols == 0 {
+		return
+	}
+
+	spec := make([]byte, ncols)
+	for i, align := range aligns {
+		spec[i] = byte(align)
+	}
+
+	io.WriteString(w, "\\begin{tabular}{"+string(spec)+"}\n\\hline\n")
+	for n, row := range rows {
+		if footer && n == len(rows)-1 && n > 0 {
+			io.WriteString(w, "\\hline\n")
+		}
+
+		cells := make([]string, ncols)
+		for i, cell := range row {
+			cells[i] = latexEscaper.Replace(cell)
+		}
+		io.WriteString(w, strings.Join(cells, " & ")+" \\\\\n")
+
+		if header && n == 0 && len(rows) > 1 {
+			io.WriteString(w, "\\hline\n")
+		}
+	}
+	io.WriteString(w, "\\hline\n\\end{tabular}\n")
+}
@@ -0,0 +1,77 @@
+package ftable
+
+import (
+	"io"
+	"strings"
+)
+
+// writeMarkdown writes rows to w as a GitHub-flavored Markdown table. If header is set, the first row is the
+// table's header. Otherwise, since a Markdown table cannot omit its header, an empty header row is written
+// above all rows. Line breaks within cells are written as <br> tags. If aligns is not nil, the delimiter row marks
+// the alignment of each column with colons, which GitHub honors, and cells are aligned to match.
+func writeMarkdown(w io.Writer, rows [][]string, aligns []Alignment, header bool) {
+	escaped := make([][]string, len(rows))
+	for i, row := range rows {
+		escaped[i] = make([]string, len(row))
+		for j, cell := range row {
+			escaped[i][j] = strings.Replace(strings.Replace(cell, "|", "\\|", -1), lineBreak, "<br>", -1)
+		}
+	}
+
+	if !header || len(escaped) == 0 {
+		escaped = append([][]string{nil}, escaped...)
+	}
+
+	widths := columnWidths(escaped)
+	if len(widths) == 0 {
+		return
+	}
+	for i, n := range widths {
+		// A delimiter row cell is at least three hyphens wide, by convention.
+		if n < 3 {
+			widths[i] = 3
+		}
+	}
+
+	sep := make([]string, len(widths))
+	for i, n := range widths {
+		sep[i] = strings.Repeat("-", n)
+		if aligns == nil {
+			continue
+		}
+		switch aligns[i] {
+		case AlignLeft:
+			sep[i] = ":" + sep[i][1:]
+		case AlignRight:
+			sep[i] = sep[i][1:] + ":"
+		case AlignCenter:
+			sep[i] = ":" + sep[i][2:] + ":"
+		}
+	}
+
+	writeRow := func(row []string) {
+		var b strings.Builder
+		b.WriteByte('|')
+		for i, n := range widths {
+			var cell string
+			if i < len(row) {
+				cell = row[i]
+			}
+			b.WriteByte(' ')
+			if aligns != nil {
+				b.WriteString(alignCell(cell, n, aligns[i], ' '))
+			} else {
+				b.WriteString(padRight(cell, n))
+			}
+			b.WriteString(" |")
+		}
+		b.WriteByte('\n')
+		io.WriteString(w, b.String())
+	}
+
+	writeRow(escaped[0])
+	writeRow(sep)
+	for _, row := range escaped[1:] {
+		writeRow(row)
+	}
+}
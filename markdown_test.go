@@ -0,0 +1,161 @@
+package ftable
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// markdownSeparator returns the delimiter row of the Markdown table out, its second line.
+func markdownSeparator(t *testing.T, out string) string {
+	t.Helper()
+	lines := strings.Split(out, "\n")
+	if len(lines) < 2 {
+		t.Fatalf("no delimiter row in %q", out)
+	}
+	return lines[1]
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	rows := [][]string{{"name", "qty", "note"}, {"apple", "3", "red|green"}, {"pear", "17", "a" + lineBreak + "b"}}
+	tests := []struct {
+		name   string
+		aligns []Alignment
+		header bool
+		want   string
+	}{
+		{
+			"unaligned", nil, true,
+			"| name  | qty | note       |\n" +
+				"| ----- | --- | ---------- |\n" +
+				"| apple | 3   | red\\|green |\n" +
+				"| pear  | 17  | a<br>b     |\n",
+		},
+		{
+			"aligned", []Alignment{AlignLeft, AlignRight, AlignCenter}, true,
+			"| name  | qty |    note    |\n" +
+				"| :---- | --: | :--------: |\n" +
+				"| apple |   3 | red\\|green |\n" +
+				"| pear  |  17 |   a<br>b   |\n",
+		},
+		{
+			"no header", nil, false,
+			"|       |     |            |\n" +
+				"| ----- | --- | ---------- |\n" +
+				"| name  | qty | note       |\n" +
+				"| apple | 3   | red\\|green |\n" +
+				"| pear  | 17  | a<br>b     |\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			writeMarkdown(&sb, rows, tt.aligns, tt.header)
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownSeparator(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts Options
+		want string
+	}{
+		{"default", "a\tb\n1\t2\n", Options{}, "| --- | --- |"},
+		{"left", "a\tb\n1\t2\n", Options{Aligns: []Alignment{AlignLeft}}, "| :-- | :-- |"},
+		{"right", "a\tb\n1\t2\n", Options{Aligns: []Alignment{AlignRight, AlignRight}}, "| --: | --: |"},
+		{"center", "a\tb\n1\t2\n", Options{Aligns: []Alignment{AlignCenter}}, "| :-: | :-- |"},
+		{"mixed", "name\tqty\tnote\n", Options{Aligns: []Alignment{AlignCenter, AlignRight, AlignLeft}},
+			"| :--: | --: | :--- |"},
+		{"autonum", "name\tqty\napple\t3\npear\t1.5\n", Options{Header: true, AutoNum: true},
+			"| :---- | --: |"},
+		{"autonum override", "name\tqty\napple\t3\n", Options{Header: true, AutoNum: true, Aligns: []Alignment{AlignCenter, AlignCenter}},
+			"| :---: | :-: |"},
+		{"number", "a\nb\n", Options{Number: true}, "| --: | :-- |"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Format = "markdown"
+			got, err := RenderString(tt.in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sep := markdownSeparator(t, got); sep != tt.want {
+				t.Errorf("delimiter row = %q, want %q in:\n%s", sep, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMarkdownRow(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"| a | b |\n", []string{"a", "b"}},
+		{"a | b", []string{"a", "b"}},
+		{"|a|b|", []string{"a", "b"}},
+		{"| red\\|green | x |", []string{"red|green", "x"}},
+		{"| a\\b | c |", []string{"a\\b", "c"}},
+		{"|  | b |", []string{"", "b"}},
+		{"| one<br>two | x |", []string{"one" + lineBreak + "two", "x"}},
+		{"no pipes here", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := markdownRow(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("markdownRow(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestRenderFromMarkdown(t *testing.T) {
+	const in = "Some fruit:\n\n" +
+		"| name  | qty | note        |\n" +
+		"| :---- | --: | :---------: |\n" +
+		"| apple |   3 | red\\|green  |\n" +
+		"| pear  |  17 | a<br>b      |\n" +
+		"\nThe end.\n"
+	got, err := RenderString(in, Options{From: "markdown", Box: true, Header: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "┏━━━━━━━┳━━━━━┳━━━━━━━━━━━┓\n" +
+		"┃ name  ┃ qty ┃ note      ┃\n" +
+		"┡━━━━━━━╇━━━━━╇━━━━━━━━━━━┩\n" +
+		"│ apple │ 3   │ red|green │\n" +
+		"│ pear  │ 17  │ a         │\n" +
+		"│       │     │ b         │\n" +
+		"└───────┴─────┴───────────┘\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// A table written without a header has its empty header row dropped.
+	got, err = RenderString("|   |   |\n|---|---|\n| a | b |\n", Options{From: "markdown", Format: "tsv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a\tb\n"; got != want {
+		t.Errorf("headerless: got %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	const in = "name\tnote\napple\tred|green\npear\ta\vb\n"
+	md, err := RenderString(in, Options{Format: "markdown", Header: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := RenderString(md, Options{From: "markdown", Format: "markdown", Header: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != md {
+		t.Errorf("round trip got:\n%s\nwant:\n%s", got, md)
+	}
+}
@@ -0,0 +1,73 @@
+package ftable
+
+// Option sets one or more of the Options of a Table created by New.
+type Option func(*Options)
+
+// New returns a Table whose Options are set by opts, in order.
+func New(opts ...Option) *Table {
+	t := &Table{}
+	for _, opt := range opts {
+		opt(&t.Options)
+	}
+	return t
+}
+
+// WithOptions sets all of a Table's Options to opts, replacing any set by earlier Option functions.
+func WithOptions(opts Options) Option {
+	return func(o *Options) { *o = opts }
+}
+
+// WithBox draws the table with box-drawing characters.
+func WithBox() Option {
+	return func(o *Options) { o.Box = true }
+}
+
+// WithHeader treats the first row as the table's header.
+func WithHeader() Option {
+	return func(o *Options) { o.Header = true }
+}
+
+// WithFooter treats the last row as the table's footer.
+func WithFooter() Option {
+	return func(o *Options) { o.Footer = true }
+}
+
+// WithRowLines draws separators between the rows of a box.
+func WithRowLines() Option {
+	return func(o *Options) { o.RowLines = true }
+}
+
+// WithMinWidth sets the minimum width of a column, including its padding.
+func WithMinWidth(n int) Option {
+	return func(o *Options) { o.MinWidth = n }
+}
+
+// WithTabWidth sets the width of a tab character, as used by the tabwriter.
+func WithTabWidth(n int) Option {
+	return func(o *Options) { o.TabWidth = n }
+}
+
+// WithPadding sets the number of padding characters added to the width of each cell.
+func WithPadding(n int) Option {
+	return func(o *Options) { o.Padding = n }
+}
+
+// WithPadChar sets the character with which cells are padded.
+func WithPadChar(r rune) Option {
+	return func(o *Options) { o.PadChar = r }
+}
+
+// WithFlags sets the tabwriter flags used to format the table.
+func WithFlags(flags uint) Option {
+	return func(o *Options) { o.Flags = flags }
+}
+
+// WithStyle sets the style in which boxes are drawn.
+func WithStyle(style Style) Option {
+	return func(o *Options) { o.Style = style }
+}
+
+// WithFormat sets the output format of the table.
+func WithFormat(format string) Option {
+	return func(o *Options) { o.Format = format }
+}
@@ -0,0 +1,45 @@
+package ftable
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	const in = "name\tqty\napple\t3\nwatermelon\t12\ntotal\t15\n"
+	opts := Options{Box: true, Header: true, Footer: true, RowLines: true, Padding: 2, PadChar: '.', Style: StyleRounded}
+	want, err := RenderString(in, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		t    *Table
+	}{
+		{"options", New(WithOptions(opts))},
+		{"functions", New(WithBox(), WithHeader(), WithFooter(), WithRowLines(), WithPadding(2), WithPadChar('.'), WithStyle(StyleRounded))},
+		{"functions in another order", New(WithStyle(StyleRounded), WithPadChar('.'), WithPadding(2), WithRowLines(), WithFooter(), WithHeader(), WithBox())},
+		{"functions after options", New(WithOptions(Options{Box: true, Padding: 2}), WithHeader(), WithFooter(), WithRowLines(), WithPadChar('.'), WithStyle(StyleRounded))},
+		{"options replacing functions", New(WithFormat("html"), WithMinWidth(20), WithOptions(opts))},
+		{"literal", &Table{Options: opts}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.t.Options, opts) {
+				t.Errorf("Options = %+v, want %+v", tt.t.Options, opts)
+			}
+			if _, err := tt.t.ReadFrom(strings.NewReader(in)); err != nil {
+				t.Fatal(err)
+			}
+			var got strings.Builder
+			if err := tt.t.Render(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got.String(), want)
+			}
+		})
+	}
+}
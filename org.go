@@ -0,0 +1,57 @@
+package ftable
+
+import (
+	"io"
+	"strings"
+)
+
+// orgEscaper escapes '|' in org-mode table cells, as the "\vert{}" entity, and replaces line breaks, which an
+// org-mode cell cannot hold, with spaces.
+var orgEscaper = strings.NewReplacer("|", `\vert{}`, lineBreak, " ")
+
+// writeOrg writes rows to w as an Emacs org-mode table, with cells aligned per aligns as org-mode would align
+// them itself. If header or footer is set, a rule separates the first or last row, respectively, from the rest.
+func writeOrg(w io.Writer, rows [][]string, aligns []Alignment, header, footer bool) {
+	escaped := make([][]string, len(rows))
+	for i, row := range rows {
+		escaped[i] = make([]string, len(row))
+		for j, cell := range row {
+			escaped[i][j] = orgEscaper.Replace(cell)
+		}
+	}
+
+	widths := columnWidths(escaped)
+	if len(widths) == 0 {
+		return
+	}
+
+	var rule strings.Builder
+	rule.WriteByte('|')
+	for i, n := range widths {
+		if i > 0 {
+			rule.WriteByte('+')
+		}
+		rule.WriteString(strings.Repeat("-", n+2))
+	}
+	rule.WriteString("|\n")
+
+	for n, row := range escaped {
+		if footer && n == len(escaped)-1 && n > 0 {
+			io.WriteString(w, rule.String())
+		}
+
+		var b strings.Builder
+		b.WriteByte('|')
+		for i, width := range widths {
+			b.WriteByte(' ')
+			b.WriteString(alignCell(cell(row, i), width, aligns[i], ' '))
+			b.WriteString(" |")
+		}
+		b.WriteByte('\n')
+		io.WriteString(w, b.String())
+
+		if header && n == 0 && len(escaped) > 1 {
+			io.WriteString(w, rule.String())
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package ftable
+
+import (
+	"bytes"
+	"strings"
+)
+
+// lineBreak separates the lines of a cell that has more than one, such as a quoted CSV field containing
+// newlines, in the tab-separated form of input. A vertical tab in input is therefore read as a line break.
+const lineBreak = "\v"
+
+// cellLines returns the lines of cell s, with any colors and hyperlinks spanning them reflowed by reflowEscapes.
+func cellLines(s string) []string {
+	lines := strings.Split(s, lineBreak)
+	if len(lines) > 1 && strings.IndexByte(s, '\x1b') >= 0 {
+		reflowEscapes(lines)
+	}
+	return lines
+}
+
+// cellWidth returns the display width of the widest line of cell s.
+func cellWidth(s string) int {
+	width, _ := measureCell(s)
+	return width
+}
+
+// measureCell returns the display width of the widest line of cell s and of its widest grapheme cluster, which
+// no wrapping of s can be narrower than.
+func measureCell(s string) (width, widest int) {
+	for _, line := range cellLines(s) {
+		w, c := measure(line)
+		if w > width {
+			width = w
+		}
+		if c > widest {
+			widest = c
+		}
+	}
+	return width, widest
+}
+
+// valignOffset returns the number of empty lines above a cell of n lines in a row height lines tall, placed
+// according to valign: centered for "middle", at the bottom of the row for "bottom", and otherwise at the top.
+func valignOffset(n, height int, valign string) int {
+	space := height - n
+	switch {
+	case space <= 0:
+		return 0
+	case valign == "middle":
+		return space / 2
+	case valign == "bottom":
+		return space
+	}
+	return 0
+}
+
+// valignLines returns lines, the lines of a cell, placed within a row height lines tall according to valign, as
+// with valignOffset.
+func valignLines(lines []string, height int, valign string) []string {
+	space := valignOffset(len(lines), height, valign)
+	if space == 0 {
+		return lines
+	}
+	return append(make([]string, space, space+len(lines)), lines...)
+}
+
+// expandLines returns rows with each row whose cells have more than one line expanded into as many rows as its
+// tallest cell has lines, with shorter cells placed per valign, and, for each returned row, the index of the row
+// in rows it came from.
+func expandLines(rows [][]string, valign string) (expanded [][]string, from []int) {
+	for n, row := range rows {
+		height := 1
+		for _, cell := range row {
+			if h := strings.Count(cell, lineBreak) + 1; h > height {
+				height = h
+			}
+		}
+		if height == 1 {
+			expanded, from = append(expanded, row), append(from, n)
+			continue
+		}
+
+		lines := make([][]string, height)
+		for i := range lines {
+			lines[i] = make([]string, len(row))
+		}
+		for i, cell := range row {
+			for h, line := range valignLines(cellLines(cell), height, valign) {
+				lines[h][i] = line
+			}
+		}
+		for _, line := range lines {
+			expanded, from = append(expanded, line), append(from, n)
+		}
+	}
+	return expanded, from
+}
+
+// splitRows splits tab-separated text into rows of cells. A trailing line ending does not produce an empty
+// final row.
+func splitRows(bs []byte) [][]string {
+	bs = bytes.TrimSuffix(bs, []byte("\n"))
+	if len(bs) == 0 {
+		return nil
+	}
+
+	lines := strings.Split(string(bs), "\n")
+	rows := make([][]string, len(lines))
+	for i, line := range lines {
+		rows[i] = strings.Split(line, "\t")
+	}
+	return rows
+}
+
+// joinRows joins rows of cells into tab-separated text, ending each row with a line ending.
+func joinRows(rows [][]string) []byte {
+	var buf bytes.Buffer
+	for _, row := range rows {
+		buf.WriteString(strings.Join(row, "\t"))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// columnWidths returns the display width of the widest cell line in each column of rows. Its length is that of
+// the longest row.
+func columnWidths(rows [][]string) []int {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := cellWidth(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	return widths
+}
+
+// padRight pads s with spaces to a display width of n.
+func padRight(s string, n int) string {
+	if pad := n - displayWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package ftable
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+// errClosed is returned by a RowWriter's methods once it has been closed.
+var errClosed = errors.New("ftable: RowWriter is closed")
+
+// cellFlattener replaces the characters that would split a cell into more than one column or row.
+var cellFlattener = strings.NewReplacer("\t", " ", "\r\n", lineBreak, "\n", lineBreak, "\r", lineBreak)
+
+// RowWriter builds a table row by row, writing it once all of its rows are known. Since every row may affect
+// the width of every column, nothing is written until Close is called.
+type RowWriter struct {
+	w      io.Writer
+	t      Table
+	closed bool
+}
+
+// NewRowWriter returns a RowWriter that writes a table formatted according to opts to w. Options affecting how
+// input is split into columns, such as Delim and CSV, do not apply to rows written with WriteRow.
+func NewRowWriter(w io.Writer, opts Options) *RowWriter {
+	return &RowWriter{w: w, t: Table{Options: opts}}
+}
+
+// WriteRow adds a row of cells to the table. Tabs in cells are replaced with spaces, and cells containing line
+// breaks take up more than one line.
+func (rw *RowWriter) WriteRow(row []string) error {
+	if rw.closed {
+		return errClosed
+	}
+
+	for i, cell := range row {
+		if i > 0 {
+			rw.t.input.WriteByte('\t')
+		}
+		if rw.t.StripColor {
+			cell = stripANSI(cell)
+		}
+		rw.t.input.WriteString(cellFlattener.Replace(cell))
+	}
+	rw.t.input.WriteByte('\n')
+	return nil
+}
+
+// Close writes the table to the RowWriter's writer, returning any error from Table.Render. A RowWriter cannot be
+// written to once closed.
+func (rw *RowWriter) Close() error {
+	if rw.closed {
+		return errClosed
+	}
+	rw.closed = true
+	return rw.t.Render(rw.w)
+}
@@ -0,0 +1,71 @@
+package ftable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRowWriter(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]string
+		opts Options
+	}{
+		{"text", [][]string{{"a", "bb"}, {"ccc", "d"}}, Options{Padding: 1}},
+		{"box", [][]string{{"name", "qty"}, {"apple", "3"}, {"watermelon", "12"}}, Options{Box: true, Header: true}},
+		{"ragged rows", [][]string{{"a"}, {"b", "c", "d"}, {}}, Options{Box: true}},
+		{"markdown", [][]string{{"x", "y"}, {"1", "2"}}, Options{Format: "markdown", Header: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var in strings.Builder
+			for _, row := range tt.rows {
+				in.WriteString(strings.Join(row, "\t") + "\n")
+			}
+			want, err := RenderString(in.String(), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got strings.Builder
+			rw := NewRowWriter(&got, tt.opts)
+			for _, row := range tt.rows {
+				if err := rw.WriteRow(row); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got.Len() > 0 {
+				t.Errorf("rows were written before Close: %q", got.String())
+			}
+			if err := rw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got.String(), want)
+			}
+		})
+	}
+}
+
+func TestRowWriterCells(t *testing.T) {
+	var got strings.Builder
+	rw := NewRowWriter(&got, Options{Box: true})
+	rw.WriteRow([]string{"tab\there", "two\nlines"})
+	if err := rw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want, err := RenderString("tab here\ttwo\vlines\n", Options{Box: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got.String(), want)
+	}
+
+	if err := rw.WriteRow([]string{"late"}); err != errClosed {
+		t.Errorf("WriteRow after Close = %v, want errClosed", err)
+	}
+	if err := rw.Close(); err != errClosed {
+		t.Errorf("second Close = %v, want errClosed", err)
+	}
+}
@@ -0,0 +1,49 @@
+package ftable
+
+import (
+	"io"
+	"strings"
+)
+
+// writeRST writes rows to w as a reStructuredText grid table. Every row is separated by a rule, since lines
+// without one between them are read as a single row of multi-line cells, as cells with line breaks are written.
+// If header is set, the first row is the table's header, separated from the body by a rule of '='.
+func writeRST(w io.Writer, rows [][]string, header bool) {
+	widths := columnWidths(rows)
+	if len(widths) == 0 {
+		return
+	}
+
+	rule := func(fill string) string {
+		var b strings.Builder
+		b.WriteByte('+')
+		for _, n := range widths {
+			b.WriteString(strings.Repeat(fill, n+2))
+			b.WriteByte('+')
+		}
+		b.WriteByte('\n')
+		return b.String()
+	}
+
+	io.WriteString(w, rule("-"))
+	for n, row := range rows {
+		lines, _ := expandLines([][]string{row}, "top")
+		var b strings.Builder
+		for _, line := range lines {
+			b.WriteByte('|')
+			for i, width := range widths {
+				b.WriteByte(' ')
+				b.WriteString(padRight(cell(line, i), width))
+				b.WriteString(" |")
+			}
+			b.WriteByte('\n')
+		}
+		io.WriteString(w, b.String())
+
+		if header && n == 0 && len(rows) > 1 {
+			io.WriteString(w, rule("="))
+		} else {
+			io.WriteString(w, rule("-"))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package ftable
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRSTGolden(t *testing.T) {
+	const in = "name\tnote\napple\tred\vor green\nkiwi\t\nwatermelon\t大きい\n"
+	tests := []struct {
+		golden string
+		opts   Options
+	}{
+		{"rst.golden", Options{Format: "rst"}},
+		{"rst-header.golden", Options{Format: "rst", Header: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			got, err := RenderString(in, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.golden, got)
+			checkGridTable(t, got, tt.opts.Header)
+		})
+	}
+}
+
+// checkGridTable fails t unless out is laid out as a reStructuredText grid table, as docutils parses one: it
+// begins and ends with a rule, every line is as wide as the first, the dividers ('|') of each line between rules
+// fall exactly on the column boundaries ('+') of the rules, and, if header is set, the second rule, and only it,
+// is drawn with '='. Cells must not hold '|' or '+' themselves.
+func checkGridTable(t *testing.T, out string, header bool) {
+	t.Helper()
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	isRule := func(line string) bool {
+		return strings.HasPrefix(line, "+") && strings.Trim(line, "+-=") == ""
+	}
+	if !isRule(lines[0]) || !isRule(lines[len(lines)-1]) {
+		t.Fatalf("grid table does not begin and end with rules:\n%s", out)
+	}
+
+	bounds := fmt.Sprint(charColumns(lines[0], '+'))
+	rules := 0
+	for n, line := range lines {
+		if w, want := displayWidth(line), displayWidth(lines[0]); w != want {
+			t.Errorf("line %d is %d wide, want %d:\n%s", n+1, w, want, out)
+		}
+		if !isRule(line) {
+			if cols := fmt.Sprint(charColumns(line, '|')); cols != bounds {
+				t.Errorf("line %d has dividers at columns %s, want %s:\n%s", n+1, cols, bounds, out)
+			}
+			continue
+		}
+		if rules++; fmt.Sprint(charColumns(line, '+')) != bounds {
+			t.Errorf("rule on line %d has boundaries at other columns than %s:\n%s", n+1, bounds, out)
+		}
+		if strings.Contains(line, "=") != (header && rules == 2) {
+			t.Errorf("line %d is the wrong kind of rule:\n%s", n+1, out)
+		}
+	}
+}
+
+// charColumns returns the display columns of line at which c appears.
+func charColumns(line string, c rune) []int {
+	var cols []int
+	col := 0
+	for _, r := range line {
+		if r == c {
+			cols = append(cols, col)
+		}
+		col += runeWidth(r)
+	}
+	return cols
+}
@@ -0,0 +1,94 @@
+package ftable
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Units of byte sizes, by powers of 1000 or of 1024.
+var (
+	siUnits     = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+	binaryUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+)
+
+// sizePrefixes are the prefixes of units larger than a byte, each a power of 1000 or 1024 greater than the last.
+const sizePrefixes = "KMGTPE"
+
+// humanizeSize returns s, a number of bytes, written with one decimal place in the largest unit in which it is at
+// least 1, such as "1.5 KiB": a power of 1024 or, if si is set, of 1000, such as "1.5 kB". If s is not a number,
+// it is returned unchanged.
+func humanizeSize(s string, si bool) string {
+	x, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsInf(x, 0) || math.IsNaN(x) {
+		return s
+	}
+
+	base, units := 1024.0, binaryUnits
+	if si {
+		base, units = 1000, siUnits
+	}
+	i := 0
+	for ; math.Abs(x) >= base && i < len(units)-1; i++ {
+		x /= base
+	}
+
+	if i == 0 {
+		return strconv.FormatFloat(x, 'f', -1, 64) + " " + units[i]
+	}
+	return strings.TrimSuffix(strconv.FormatFloat(x, 'f', 1, 64), ".0") + " " + units[i]
+}
+
+// dehumanizeSize returns s, a size such as "1.5 KiB" or "2M", as a whole number of bytes. If s is not a size, it is
+// returned unchanged.
+func dehumanizeSize(s string, si bool) string {
+	x, ok := parseSize(s, si)
+	if !ok {
+		return s
+	}
+	return strconv.FormatFloat(math.Round(x), 'f', -1, 64)
+}
+
+// parseSize returns the number of bytes in s, a number optionally followed by a unit: B for bytes, or a prefix of
+// sizePrefixes, in either case, optionally followed by i, for a power of 1024, and B. A prefix without i is a
+// power of 1024 or, if si is set, of 1000.
+func parseSize(s string, si bool) (float64, bool) {
+	s = strings.TrimSpace(s)
+	n := len(s)
+	for n > 0 && (s[n-1] >= 'A' && s[n-1] <= 'Z' || s[n-1] >= 'a' && s[n-1] <= 'z') {
+		n--
+	}
+	x, err := strconv.ParseFloat(strings.TrimSpace(s[:n]), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	unit := s[n:]
+	if unit == "" || unit == "B" || unit == "b" {
+		return x, true
+	}
+
+	p := strings.IndexByte(sizePrefixes, strings.ToUpper(unit[:1])[0])
+	if p < 0 {
+		return 0, false
+	}
+	base := 1024.0
+	switch unit[1:] {
+	case "i", "iB":
+	case "", "B":
+		if si {
+			base = 1000
+		}
+	default:
+		return 0, false
+	}
+	return x * math.Pow(base, float64(p+1)), true
+}
+
+// isByteSize reports whether s is a size with an explicit unit of bytes, such as "512 B" or "2 kB". Sizes without
+// one, such as "5m" or "2e", read as words too often to be taken for numbers.
+func isByteSize(s string) bool {
+	s = strings.TrimSpace(s)
+	_, ok := parseSize(s, false)
+	return ok && strings.HasSuffix(s, "B")
+}
@@ -0,0 +1,106 @@
+package ftable
+
+import "testing"
+
+func TestHumanizeSize(t *testing.T) {
+	tests := []struct {
+		s    string
+		si   bool
+		want string
+	}{
+		{"0", false, "0 B"},
+		{"512", false, "512 B"},
+		{"1023", false, "1023 B"},
+		{"1024", false, "1 KiB"},
+		{"1536", false, "1.5 KiB"},
+		{"1048576", false, "1 MiB"},
+		{"5368709120", false, "5 GiB"},
+		{"1649267441664", false, "1.5 TiB"},
+		{"-2048", false, "-2 KiB"},
+		{"999", true, "999 B"},
+		{"1000", true, "1 kB"},
+		{"1536", true, "1.5 kB"},
+		{"2500000", true, "2.5 MB"},
+		{"7000000000000", true, "7 TB"},
+		{"1e21", true, "1000 EB"},
+		{"0.5", false, "0.5 B"},
+		{" 2048 ", false, "2 KiB"},
+		{"n/a", false, "n/a"},
+		{"", false, ""},
+		{"Inf", false, "Inf"},
+	}
+	for _, tt := range tests {
+		if got := humanizeSize(tt.s, tt.si); got != tt.want {
+			t.Errorf("humanizeSize(%q, %v) = %q, want %q", tt.s, tt.si, got, tt.want)
+		}
+	}
+}
+
+func TestDehumanizeSize(t *testing.T) {
+	tests := []struct {
+		s    string
+		si   bool
+		want string
+	}{
+		{"512", false, "512"},
+		{"512 B", false, "512"},
+		{"1.5 KiB", false, "1536"},
+		{"1.5 KiB", true, "1536"},
+		{"1.5K", false, "1536"},
+		{"1.5K", true, "1500"},
+		{"2 kB", true, "2000"},
+		{"2kb", false, "2kb"},
+		{"3 MiB", true, "3145728"},
+		{"1G", false, "1073741824"},
+		{"1 TB", true, "1000000000000"},
+		{"1.5 PiB", false, "1688849860263936"},
+		{"0.3 KiB", false, "307"},
+		{"1 XB", false, "1 XB"},
+		{"1 Kx", false, "1 Kx"},
+		{"big", false, "big"},
+		{"", false, ""},
+	}
+	for _, tt := range tests {
+		if got := dehumanizeSize(tt.s, tt.si); got != tt.want {
+			t.Errorf("dehumanizeSize(%q, %v) = %q, want %q", tt.s, tt.si, got, tt.want)
+		}
+	}
+}
+
+func TestSizeRoundTrip(t *testing.T) {
+	// Sizes are written to one decimal place, so only those that it holds exactly survive the round trip.
+	tests := []struct {
+		si    bool
+		sizes []string
+	}{
+		{false, []string{"1", "1024", "1536", "1048576", "3221225472"}},
+		{true, []string{"1", "1000", "1500", "1000000", "3200000000"}},
+	}
+	for _, tt := range tests {
+		for _, s := range tt.sizes {
+			if got := dehumanizeSize(humanizeSize(s, tt.si), tt.si); got != s {
+				t.Errorf("si=%v: %s became %s", tt.si, s, got)
+			}
+		}
+	}
+}
+
+func TestRenderHumanize(t *testing.T) {
+	const in = "file\tsize\na\t512\nb\t1536\nc\t10485760\n"
+	got, err := RenderString(in, Options{Header: true, Padding: 1, AutoNum: true, Humanize: []int{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "file    size\n" +
+		"a      512 B\n" +
+		"b    1.5 KiB\n" +
+		"c     10 MiB\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	got = renderTSV(t, "size\n1.5 kB\n2M\n", Options{Header: true, Dehumanize: []int{0}, SizeSI: true})
+	if want := "size\n1500\n2000000\n"; got != want {
+		t.Errorf("dehumanized: got %q, want %q", got, want)
+	}
+}
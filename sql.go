@@ -0,0 +1,49 @@
+package ftable
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeSQL writes rows to w as SQL INSERT statements into table, one per row after the header row, which names
+// the columns. Cells are written as string literals, with their line breaks as newlines, and empty cells as NULL
+// if nulls is set. It returns an error if any row has more cells than the header.
+func writeSQL(w io.Writer, rows [][]string, table string, nulls bool) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	cols := make([]string, len(rows[0]))
+	for i, name := range rows[0] {
+		cols[i] = sqlQuote(name, '"')
+	}
+	prefix := "INSERT INTO " + table + " (" + strings.Join(cols, ", ") + ") VALUES ("
+
+	for n, row := range rows[1:] {
+		if len(row) > len(cols) {
+			return fmt.Errorf("row %d has %d fields, but the header has only %d", n+2, len(row), len(cols))
+		}
+
+		values := make([]string, len(cols))
+		for i := range values {
+			c := cell(row, i)
+			if c == "" && nulls {
+				values[i] = "NULL"
+			} else {
+				values[i] = sqlQuote(c, '\'')
+			}
+		}
+		if _, err := io.WriteString(w, prefix+strings.Join(values, ", ")+");\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqlQuote returns s, with its line breaks as newlines, quoted by q: either ' for a string literal or " for an
+// identifier. Quotes within s are doubled.
+func sqlQuote(s string, q byte) string {
+	s = strings.Replace(s, lineBreak, "\n", -1)
+	return string(q) + strings.Replace(s, string(q), string(q)+string(q), -1) + string(q)
+}
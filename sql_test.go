@@ -0,0 +1,84 @@
+package ftable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSQLQuote(t *testing.T) {
+	tests := []struct {
+		s    string
+		q    byte
+		want string
+	}{
+		{"apple", '\'', "'apple'"},
+		{"O'Brien", '\'', "'O''Brien'"},
+		{"''", '\'', "''''''"},
+		{`say "hi"`, '\'', `'say "hi"'`},
+		{"", '\'', "''"},
+		{"a" + lineBreak + "b", '\'', "'a\nb'"},
+		{"name", '"', `"name"`},
+		{`odd "col"`, '"', `"odd ""col"""`},
+		{"it's", '"', `"it's"`},
+	}
+	for _, tt := range tests {
+		if got := sqlQuote(tt.s, tt.q); got != tt.want {
+			t.Errorf("sqlQuote(%q, %q) = %s, want %s", tt.s, tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestWriteSQL(t *testing.T) {
+	rows := [][]string{{"name", "note"}, {"O'Brien", ""}, {"pear"}, {"", "it''s"}}
+	tests := []struct {
+		name  string
+		nulls bool
+		want  string
+	}{
+		{
+			"empty strings", false,
+			`INSERT INTO people ("name", "note") VALUES ('O''Brien', '');` + "\n" +
+				`INSERT INTO people ("name", "note") VALUES ('pear', '');` + "\n" +
+				`INSERT INTO people ("name", "note") VALUES ('', 'it''''s');` + "\n",
+		},
+		{
+			"nulls", true,
+			`INSERT INTO people ("name", "note") VALUES ('O''Brien', NULL);` + "\n" +
+				`INSERT INTO people ("name", "note") VALUES ('pear', NULL);` + "\n" +
+				`INSERT INTO people ("name", "note") VALUES (NULL, 'it''''s');` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := writeSQL(&sb, rows, "people", tt.nulls); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSQL(t *testing.T) {
+	got, err := RenderString("name\tqty\nsmith's\t3\n\t\n", Options{Format: "sql", Header: true, SQLTable: "stock", SQLNulls: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `INSERT INTO stock ("name", "qty") VALUES ('smith''s', '3');` + "\n" +
+		`INSERT INTO stock ("name", "qty") VALUES (NULL, NULL);` + "\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := RenderString("a\nb\tc\n", Options{Format: "sql", Header: true, SQLTable: "t"}); err == nil {
+		t.Error("no error for a row longer than the header")
+	}
+	if _, err := RenderString("a\n", Options{Format: "sql", Header: true}); err == nil {
+		t.Error("no error without SQLTable")
+	}
+	if _, err := RenderString("a\n", Options{Format: "sql", SQLTable: "t"}); err == nil {
+		t.Error("no error without Header")
+	}
+}
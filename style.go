@@ -0,0 +1,140 @@
+package ftable
+
+import "sort"
+
+// boxBorder is the set of glyphs drawing a horizontal border of a box: its left and right ends, the line between
+// them, and the joins at each column boundary.
+type boxBorder struct {
+	left, fill, join, right string
+}
+
+// boxStyle is the set of glyphs used to draw a box. Heavy glyphs are used around header and footer rows, and
+// light glyphs around all others.
+type boxStyle struct {
+	// top and bottom are the borders above the first row and below the last, depending on whether that row is
+	// heavy.
+	top, heavyTop       boxBorder
+	bottom, heavyBottom boxBorder
+
+	// sep separates two light rows, heavySep two heavy rows, and heavyLight and lightHeavy a heavy row from
+	// the light row below it and a light row from the heavy row below it, respectively.
+	sep, heavySep, heavyLight, lightHeavy boxBorder
+
+	// double separates a header drawn in the light style from the rows below it, with a double line where the
+	// style has one.
+	double boxBorder
+
+	// div and heavyDiv divide the cells of light and heavy rows.
+	div, heavyDiv string
+}
+
+// Style names a style in which boxes may be drawn.
+type Style string
+
+// Box styles.
+const (
+	// StyleLight draws light borders, save for heavy borders around the header and footer. It is the default.
+	StyleLight Style = "light"
+	// StyleHeavy draws heavy borders throughout.
+	StyleHeavy Style = "heavy"
+	// StyleRounded draws light borders with rounded corners, setting apart the header and footer with double
+	// lines.
+	StyleRounded Style = "rounded"
+	// StyleDouble draws double borders, with light lines between rows.
+	StyleDouble Style = "double"
+	// StyleASCII draws borders with ASCII characters only.
+	StyleASCII Style = "ascii"
+)
+
+// boxStyles are the glyphs of each Style.
+var boxStyles = map[Style]*boxStyle{
+	// light draws light borders, save for heavy borders around the header and footer.
+	StyleLight: {
+		top:         boxBorder{"┌", "─", "┬", "┐"},
+		heavyTop:    boxBorder{"┏", "━", "┳", "┓"},
+		bottom:      boxBorder{"└", "─", "┴", "┘"},
+		heavyBottom: boxBorder{"┗", "━", "┻", "┛"},
+		sep:         boxBorder{"├", "─", "┼", "┤"},
+		heavySep:    boxBorder{"┣", "━", "╋", "┫"},
+		heavyLight:  boxBorder{"┡", "━", "╇", "┩"},
+		lightHeavy:  boxBorder{"┢", "━", "╈", "┪"},
+		double:      boxBorder{"╞", "═", "╪", "╡"},
+		div:         "│",
+		heavyDiv:    "┃",
+	},
+
+	// heavy draws heavy borders throughout.
+	StyleHeavy: {
+		top:         boxBorder{"┏", "━", "┳", "┓"},
+		heavyTop:    boxBorder{"┏", "━", "┳", "┓"},
+		bottom:      boxBorder{"┗", "━", "┻", "┛"},
+		heavyBottom: boxBorder{"┗", "━", "┻", "┛"},
+		sep:         boxBorder{"┣", "━", "╋", "┫"},
+		heavySep:    boxBorder{"┣", "━", "╋", "┫"},
+		heavyLight:  boxBorder{"┣", "━", "╋", "┫"},
+		lightHeavy:  boxBorder{"┣", "━", "╋", "┫"},
+		double:      boxBorder{"┣", "━", "╋", "┫"},
+		div:         "┃",
+		heavyDiv:    "┃",
+	},
+
+	// rounded draws light borders with rounded corners. Since there are no heavy rounded corners, the header and
+	// footer are instead set apart by double lines.
+	StyleRounded: {
+		top:         boxBorder{"╭", "─", "┬", "╮"},
+		heavyTop:    boxBorder{"╭", "─", "┬", "╮"},
+		bottom:      boxBorder{"╰", "─", "┴", "╯"},
+		heavyBottom: boxBorder{"╰", "─", "┴", "╯"},
+		sep:         boxBorder{"├", "─", "┼", "┤"},
+		heavySep:    boxBorder{"╞", "═", "╪", "╡"},
+		heavyLight:  boxBorder{"╞", "═", "╪", "╡"},
+		lightHeavy:  boxBorder{"╞", "═", "╪", "╡"},
+		double:      boxBorder{"╞", "═", "╪", "╡"},
+		div:         "│",
+		heavyDiv:    "│",
+	},
+
+	// double draws double borders around the box and its columns, with light lines between rows and double lines
+	// setting apart the header and footer.
+	StyleDouble: {
+		top:         boxBorder{"╔", "═", "╦", "╗"},
+		heavyTop:    boxBorder{"╔", "═", "╦", "╗"},
+		bottom:      boxBorder{"╚", "═", "╩", "╝"},
+		heavyBottom: boxBorder{"╚", "═", "╩", "╝"},
+		sep:         boxBorder{"╟", "─", "╫", "╢"},
+		heavySep:    boxBorder{"╠", "═", "╬", "╣"},
+		heavyLight:  boxBorder{"╠", "═", "╬", "╣"},
+		lightHeavy:  boxBorder{"╠", "═", "╬", "╣"},
+		double:      boxBorder{"╠", "═", "╬", "╣"},
+		div:         "║",
+		heavyDiv:    "║",
+	},
+
+	// ascii draws boxes with ASCII characters only, for terminals and logs that mangle box-drawing characters.
+	// Heavy borders are drawn with '='.
+	StyleASCII: {
+		top:         boxBorder{"+", "-", "+", "+"},
+		heavyTop:    boxBorder{"+", "=", "+", "+"},
+		bottom:      boxBorder{"+", "-", "+", "+"},
+		heavyBottom: boxBorder{"+", "=", "+", "+"},
+		sep:         boxBorder{"+", "-", "+", "+"},
+		heavySep:    boxBorder{"+", "=", "+", "+"},
+		heavyLight:  boxBorder{"+", "=", "+", "+"},
+		lightHeavy:  boxBorder{"+", "=", "+", "+"},
+		double:      boxBorder{"+", "=", "+", "+"},
+		div:         "|",
+		heavyDiv:    "|",
+	},
+}
+
+// BoxStyles returns the styles in which boxes may be drawn, sorted by name.
+func BoxStyles() []Style {
+	styles := make([]Style, 0, len(boxStyles))
+	for style := range boxStyles {
+		styles = append(styles, style)
+	}
+	sort.Slice(styles, func(i, j int) bool {
+		return styles[i] < styles[j]
+	})
+	return styles
+}
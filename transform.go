@@ -0,0 +1,662 @@
+package ftable
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// transform applies the row transformations selected by opts to rows, returning the rows to format.
+func (opts *Options) transform(rows [][]string) [][]string {
+	if opts.ShowControl != "" {
+		for _, row := range rows {
+			for i, cell := range row {
+				row[i] = showControl(cell, opts.ShowControl == "picture")
+			}
+		}
+	}
+
+	if opts.TrimLeft || opts.TrimRight {
+		for _, row := range rows {
+			for i, cell := range row {
+				row[i] = trimCell(cell, opts.TrimLeft && !(opts.KeepIndent && i == 0), opts.TrimRight)
+			}
+		}
+	}
+
+	if opts.Transpose {
+		rows = transpose(rows)
+	}
+
+	// Until the aggregate row is added as the footer, every row but the header is data.
+	input := *opts
+	if opts.Aggregates != nil {
+		input.Footer = false
+	}
+	header, data, footer := input.sections(rows)
+
+	if opts.Dates != nil {
+		for _, row := range data {
+			for _, d := range opts.Dates {
+				if d.Col < len(row) {
+					row[d.Col] = formatDate(row[d.Col], d.Layout, opts.DateInput)
+				}
+			}
+		}
+	}
+
+	if opts.Dehumanize != nil {
+		for _, row := range data {
+			for _, i := range opts.Dehumanize {
+				if i < len(row) {
+					row[i] = dehumanizeSize(row[i], opts.SizeSI)
+				}
+			}
+		}
+	}
+
+	if opts.Filters != nil {
+		data = filterRows(data, opts.Filters, opts.IgnoreCase)
+	}
+
+	if opts.Unique || opts.UniqueBy > 0 {
+		data = uniqueRows(data, opts.UniqueBy-1)
+	}
+
+	switch {
+	case opts.SortKeys != nil:
+		opts.sortByKeys(data, opts.SortKeys)
+	case opts.SortCol > 0:
+		less := opts.sortLess()
+		if opts.IgnoreCase {
+			less = foldLess(less)
+		}
+		sortRows(data, opts.SortCol-1, less, opts.SortReverse)
+	}
+
+	if opts.Head > 0 && len(data) > opts.Head {
+		data = data[:opts.Head]
+	}
+	if opts.Tail > 0 && len(data) > opts.Tail {
+		data = data[len(data)-opts.Tail:]
+	}
+	rows = joinSections(header, data, footer)
+
+	var total []string
+	if opts.Aggregates != nil {
+		total = aggregateRow(data, opts.Aggregates)
+	}
+
+	// Records for other programs are only sorted into their groups, without blanked keys or subtotal rows.
+	var roles []groupRole
+	if opts.GroupBy > 0 {
+		sortRows(data, opts.GroupBy-1, lessLexical, false)
+		if !opts.dataFormat() {
+			data, roles = groupRows(data, opts.GroupBy-1, opts.Aggregates)
+		}
+		rows = joinSections(header, data, footer)
+	}
+
+	if total != nil {
+		rows = append(rows, total)
+	}
+
+	if roles != nil {
+		opts.dataIndex = make([]int, len(rows))
+		for i := range opts.dataIndex {
+			opts.dataIndex[i] = -1
+		}
+		n := 0
+		for i, role := range roles {
+			if role != groupSubtotal {
+				opts.dataIndex[len(header)+i] = n
+				n++
+			}
+		}
+	}
+
+	if opts.Collapse != nil {
+		_, data, _ := opts.sections(rows)
+		for _, col := range opts.Collapse {
+			collapseRows(withoutSubtotals(data, roles), col)
+		}
+	}
+
+	if opts.Humanize != nil {
+		_, data, footer := opts.sections(rows)
+		for _, sec := range [][][]string{data, footer} {
+			for _, row := range sec {
+				for _, i := range opts.Humanize {
+					if i < len(row) {
+						row[i] = humanizeSize(row[i], opts.SizeSI)
+					}
+				}
+			}
+		}
+	}
+
+	if opts.Checks != nil {
+		marks := checkMarks
+		if opts.Style == StyleASCII {
+			marks = asciiCheckMarks
+		}
+		_, data, _ := opts.sections(rows)
+		for _, row := range withoutSubtotals(data, roles) {
+			for _, i := range opts.Checks {
+				if i < len(row) {
+					row[i] = checkMark(row[i], marks)
+				}
+			}
+		}
+	}
+
+	// Escape sequences are only for terminals, never for records other programs read.
+	if opts.Link > 0 && !opts.dataFormat() {
+		_, data, _ := opts.sections(rows)
+		for _, row := range withoutSubtotals(data, roles) {
+			if i := opts.Link - 1; i < len(row) {
+				url := row[i]
+				if opts.LinkURL > 0 {
+					url = cell(row, opts.LinkURL-1)
+				}
+				row[i] = hyperlink(row[i], stripANSI(url))
+			}
+		}
+	}
+
+	if opts.Cols != nil {
+		cols := opts.Cols
+		if opts.Exclude != nil {
+			cols = excludeColumns(cols, opts.Exclude)
+		}
+		for n, row := range rows {
+			rows[n] = selectColumns(row, cols)
+		}
+	} else if opts.Exclude != nil {
+		for n, row := range rows {
+			rows[n] = dropColumns(row, opts.Exclude)
+		}
+	}
+
+	if opts.Grouping != 0 {
+		_, data, footer := opts.sections(rows)
+		for i, numeric := range numericColumns(data, 0, "") {
+			if !numeric {
+				continue
+			}
+			for _, sec := range [][][]string{data, footer} {
+				for _, row := range sec {
+					if i < len(row) {
+						row[i] = groupDigits(row[i], opts.Grouping)
+					}
+				}
+			}
+		}
+	}
+
+	if opts.PadRows {
+		ncols := len(columnWidths(rows))
+		for n, row := range rows {
+			if len(row) < ncols {
+				rows[n] = append(row, make([]string, ncols-len(row))...)
+			}
+		}
+	}
+
+	// The blanked keys of grouped rows and the cells of subtotal rows that aggregate nothing are left empty.
+	if opts.Empty != "" {
+		_, data, _ := opts.sections(rows)
+		var keys []int
+		if roles != nil {
+			keys = opts.selectedColumns(opts.GroupBy - 1)
+		}
+		for n, row := range data {
+			if roles != nil && roles[n] == groupSubtotal {
+				continue
+			}
+			for i, cell := range row {
+				if cell == "" && !(roles != nil && roles[n] == groupMember && hasColumn(keys, i)) {
+					row[i] = opts.Empty
+				}
+			}
+		}
+	}
+
+	if opts.Number {
+		header, data, footer := opts.sections(rows)
+		for _, sec := range [][][]string{header, footer} {
+			for n, row := range sec {
+				sec[n] = append([]string{""}, row...)
+			}
+		}
+		for n, row := range data {
+			num := strconv.Itoa(opts.NumberFrom + n)
+			if opts.dataIndex != nil {
+				num = ""
+				if i := opts.dataIndex[len(header)+n]; i >= 0 {
+					num = strconv.Itoa(opts.NumberFrom + i)
+				}
+			}
+			data[n] = append([]string{num}, row...)
+		}
+	}
+
+	if opts.Highlights != nil && !opts.dataFormat() {
+		_, data, _ := opts.sections(rows)
+		for _, h := range opts.Highlights {
+			for _, i := range opts.outputColumns(h.Col) {
+				highlightCells(withoutSubtotals(data, roles), i, h.Func, opts.Grouping, opts.HighlightColor)
+			}
+		}
+	}
+
+	if opts.Match != nil && !opts.dataFormat() {
+		for _, row := range rows {
+			for i, cell := range row {
+				row[i] = highlightMatches(cell, opts.Match, opts.MatchColor)
+			}
+		}
+	}
+
+	if opts.MaxCol > 0 {
+		for _, row := range rows {
+			for i, cell := range row {
+				lines := cellLines(cell)
+				for h, line := range lines {
+					lines[h] = truncate(line, opts.MaxCol, opts.Ellipsis)
+				}
+				row[i] = strings.Join(lines, lineBreak)
+			}
+		}
+	}
+
+	// Boxes wrap cells to their columns' maximum widths instead.
+	if opts.ColWidths != nil && !opts.Box {
+		truncateColumns(rows, opts.ColWidths, opts.Ellipsis)
+	}
+	return rows
+}
+
+// sections splits rows into its header, data, and footer rows, according to opts. The returned slices share
+// rows' backing array.
+func (opts *Options) sections(rows [][]string) (header, data, footer [][]string) {
+	data = rows
+	if opts.Header && len(data) > 0 {
+		header, data = data[:1], data[1:]
+	}
+	if opts.Footer && len(data) > 0 {
+		data, footer = data[:len(data)-1], data[len(data)-1:]
+	}
+	return header, data, footer
+}
+
+// cell returns the cell of row in column i, or an empty string if row is too short to have one.
+func cell(row []string, i int) string {
+	if i < len(row) {
+		return row[i]
+	}
+	return ""
+}
+
+// collapseRows blanks the cell of each of rows in column col, in place, that repeats the cell of the row before it.
+func collapseRows(rows [][]string, col int) {
+	prev := ""
+	for n, row := range rows {
+		if col >= len(row) {
+			prev = ""
+			continue
+		}
+		c := row[col]
+		if n > 0 && c == prev {
+			row[col] = ""
+		}
+		prev = c
+	}
+}
+
+// uniqueRows returns rows without the rows that duplicate an earlier one: in their cell in column col or, if col
+// is negative, in all their cells.
+func uniqueRows(rows [][]string, col int) [][]string {
+	seen := make(map[string]bool)
+	var kept [][]string
+	for _, row := range rows {
+		var key string
+		if col < 0 {
+			// Cells cannot contain tabs, so rows joined by them are equal only if their cells are.
+			key = strings.Join(row, "\t")
+		} else {
+			key = cell(row, col)
+		}
+		if !seen[key] {
+			seen[key] = true
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+// joinSections returns a new slice of the rows of header, data, and footer, in order.
+func joinSections(header, data, footer [][]string) [][]string {
+	rows := make([][]string, 0, len(header)+len(data)+len(footer))
+	return append(append(append(rows, header...), data...), footer...)
+}
+
+// sortRows stably sorts rows by their cells in column i, compared by less, in descending order if reverse is set.
+func sortRows(rows [][]string, i int, less func(a, b string) bool, reverse bool) {
+	sort.SliceStable(rows, func(x, y int) bool {
+		a, b := cell(rows[x], i), cell(rows[y], i)
+		if reverse {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+}
+
+// SortKey is a column by which to sort rows.
+type SortKey struct {
+	// Col is the 0-based index of the column to sort by.
+	Col int
+	// Reverse sorts by the column in descending order.
+	Reverse bool
+	// Compare names the comparison of the column's cells: "numeric", "natural", or "lexical", as with SortNumeric
+	// and SortNatural, or the comparison that they select if empty.
+	Compare string
+}
+
+// sortSuffixes maps the suffixes of sort keys parsed by ParseSortKeys to their effects on a key.
+var sortSuffixes = map[byte]func(*SortKey){
+	'a': func(k *SortKey) { k.Reverse = false },
+	'd': func(k *SortKey) { k.Reverse = true },
+	'n': func(k *SortKey) { k.Compare = "numeric" },
+	'v': func(k *SortKey) { k.Compare = "natural" },
+	'l': func(k *SortKey) { k.Compare = "lexical" },
+}
+
+// ParseSortKeys parses a comma-separated list of sort keys, each a 1-based column number followed by any of the
+// suffixes "a" or "d", for ascending or descending order, and "n", "v", or "l", for numeric, natural, or lexical
+// comparison, such as "2d,1" or "3nd".
+func ParseSortKeys(v string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, field := range strings.Split(v, ",") {
+		i := strings.IndexFunc(field, func(r rune) bool { return r < '0' || r > '9' })
+		if i < 0 {
+			i = len(field)
+		}
+
+		n, err := strconv.Atoi(field[:i])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid sort column %q", field)
+		}
+
+		key := SortKey{Col: n - 1}
+		for j := i; j < len(field); j++ {
+			set, ok := sortSuffixes[field[j]]
+			if !ok {
+				return nil, fmt.Errorf("unrecognized suffix %q in sort key %q", field[j], field)
+			}
+			set(&key)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// sortByKeys stably sorts rows by each of keys in turn, comparing their cells in the column of each key only if
+// they are equal in those of the keys before it. SortReverse reverses the whole order.
+func (opts *Options) sortByKeys(rows [][]string, keys []SortKey) {
+	less := make([]func(a, b string) bool, len(keys))
+	for i, key := range keys {
+		switch key.Compare {
+		case "numeric":
+			less[i] = lessNumeric
+		case "natural":
+			less[i] = lessNatural
+		case "lexical":
+			less[i] = lessLexical
+		default:
+			less[i] = opts.sortLess()
+		}
+		if opts.IgnoreCase {
+			less[i] = foldLess(less[i])
+		}
+	}
+
+	sort.SliceStable(rows, func(x, y int) bool {
+		for i, key := range keys {
+			a, b := cell(rows[x], key.Col), cell(rows[y], key.Col)
+			if key.Reverse != opts.SortReverse {
+				a, b = b, a
+			}
+			switch {
+			case less[i](a, b):
+				return true
+			case less[i](b, a):
+				return false
+			}
+		}
+		return false
+	})
+}
+
+// sortLess returns the comparison by which opts sort cells: numeric, natural, or otherwise lexical.
+func (opts *Options) sortLess() func(a, b string) bool {
+	switch {
+	case opts.SortNumeric:
+		return lessNumeric
+	case opts.SortNatural:
+		return lessNatural
+	}
+	return lessLexical
+}
+
+// foldLess returns less comparing its cells without regard to case.
+func foldLess(less func(a, b string) bool) func(a, b string) bool {
+	return func(a, b string) bool {
+		return less(strings.ToLower(a), strings.ToLower(b))
+	}
+}
+
+// lessLexical reports whether a sorts before b, byte by byte.
+func lessLexical(a, b string) bool {
+	return a < b
+}
+
+// lessNumeric reports whether a sorts before b by their numeric values, with cells that are not numbers sorted
+// lexically after those that are.
+func lessNumeric(a, b string) bool {
+	x, xerr := strconv.ParseFloat(a, 64)
+	y, yerr := strconv.ParseFloat(b, 64)
+	switch {
+	case xerr == nil && yerr == nil:
+		return x < y
+	case xerr == nil || yerr == nil:
+		return xerr == nil
+	}
+	return a < b
+}
+
+// lessNatural reports whether a sorts before b in natural order, comparing runs of digits by their numeric
+// values and all else lexically, so that "item2" sorts before "item10". Runs of digits with equal values, such
+// as "7" and "007", are ordered by their number of leading zeros, fewest first.
+func lessNatural(a, b string) bool {
+	for a != "" && b != "" {
+		x, y := naturalRun(a), naturalRun(b)
+		a, b = a[len(x):], b[len(y):]
+		if !isDigit(x[0]) || !isDigit(y[0]) {
+			if x != y {
+				return x < y
+			}
+			continue
+		}
+
+		xv, yv := strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+		switch {
+		case len(xv) != len(yv):
+			return len(xv) < len(yv)
+		case xv != yv:
+			return xv < yv
+		case len(x) != len(y):
+			return len(x) < len(y)
+		}
+	}
+	return len(a) < len(b)
+}
+
+// naturalRun returns the run of digits or non-digits at the start of s, which must not be empty.
+func naturalRun(s string) string {
+	digit := isDigit(s[0])
+	n := 1
+	for n < len(s) && isDigit(s[n]) == digit {
+		n++
+	}
+	return s[:n]
+}
+
+// isDigit reports whether c is an ASCII decimal digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// ParseColumns parses a comma-separated list of 1-based column numbers and inclusive ranges of them, such as
+// "1-3", returning their 0-based indices.
+func ParseColumns(v string) ([]int, error) {
+	var cols []int
+	for _, field := range strings.Split(v, ",") {
+		lo, hi := field, field
+		if i := strings.Index(field, "-"); i > 0 {
+			lo, hi = field[:i], field[i+1:]
+		}
+
+		first, err := strconv.Atoi(lo)
+		if err != nil || first < 1 {
+			return nil, fmt.Errorf("invalid column %q", field)
+		}
+		last, err := strconv.Atoi(hi)
+		if err != nil || last < first {
+			return nil, fmt.Errorf("invalid column range %q", field)
+		}
+
+		for i := first; i <= last; i++ {
+			cols = append(cols, i-1)
+		}
+	}
+	return cols, nil
+}
+
+// selectColumns returns the cells of row in the columns cols. Columns that row does not have are empty.
+func selectColumns(row []string, cols []int) []string {
+	selected := make([]string, len(cols))
+	for i, col := range cols {
+		selected[i] = cell(row, col)
+	}
+	return selected
+}
+
+// excludeColumns returns the columns of cols that are not among exclude.
+func excludeColumns(cols, exclude []int) []int {
+	var kept []int
+	for _, col := range cols {
+		if !hasColumn(exclude, col) {
+			kept = append(kept, col)
+		}
+	}
+	return kept
+}
+
+// dropColumns returns the cells of row that are not in the columns exclude, in order.
+func dropColumns(row []string, exclude []int) []string {
+	kept := make([]string, 0, len(row))
+	for i, cell := range row {
+		if !hasColumn(exclude, i) {
+			kept = append(kept, cell)
+		}
+	}
+	return kept
+}
+
+// hasColumn reports whether cols includes col.
+func hasColumn(cols []int, col int) bool {
+	for _, c := range cols {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
+
+// transpose returns the columns of rows as rows. Short rows are padded with empty cells.
+func transpose(rows [][]string) [][]string {
+	cols := make([][]string, len(columnWidths(rows)))
+	for i := range cols {
+		cols[i] = make([]string, len(rows))
+		for n, row := range rows {
+			cols[i][n] = cell(row, i)
+		}
+	}
+	return cols
+}
+
+// decimalNumber matches a decimal number, capturing its sign, integer digits, and the remainder.
+var decimalNumber = regexp.MustCompile(`^([+-]?)([0-9]+)(\.[0-9]*)?$`)
+
+// groupDigits returns s with sep inserted between each group of three of its integer digits, counting from the
+// decimal point, if s is a decimal number. Otherwise, s is returned unchanged.
+func groupDigits(s string, sep rune) string {
+	m := decimalNumber.FindStringSubmatch(s)
+	if m == nil {
+		return s
+	}
+
+	sign, digits, frac := m[1], m[2], m[3]
+	var sb strings.Builder
+	sb.WriteString(sign)
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteRune(sep)
+		}
+		sb.WriteRune(d)
+	}
+	sb.WriteString(frac)
+	return sb.String()
+}
+
+// trimCell returns s with the whitespace at the start of each of its lines removed, if left is set, and at the
+// end of each, if right is set.
+func trimCell(s string, left, right bool) string {
+	lines := cellLines(s)
+	for i, line := range lines {
+		if left {
+			line = strings.TrimLeftFunc(line, unicode.IsSpace)
+		}
+		if right {
+			line = strings.TrimRightFunc(line, unicode.IsSpace)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, lineBreak)
+}
+
+// truncate returns s, shortened to no more than width display columns by replacing its end with ellipsis, if
+// it is any wider.
+func truncate(s string, width int, ellipsis string) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+
+	room := width - displayWidth(ellipsis)
+	if room <= 0 {
+		head, _ := splitWidth(ellipsis, width)
+		return head
+	}
+
+	// Escape sequences in the text cut off are kept, so that colors are still reset and hyperlinks closed.
+	head, rest := splitWidth(s, room)
+	if displayWidth(head) > room {
+		// The first cluster of s alone is too wide.
+		return ellipsis + escapes(s)
+	}
+	return head + ellipsis + escapes(rest)
+}
@@ -0,0 +1,558 @@
+package ftable
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// renderTSV returns in rendered with opts in the tsv format, so that the rows it transforms can be compared
+// without their layout.
+func renderTSV(t *testing.T, in string, opts Options) string {
+	t.Helper()
+	opts.Format = "tsv"
+	got, err := RenderString(in, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestSort(t *testing.T) {
+	const in = "name\tsize\nb\t10\nc\t9\na\t100\n"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"lexical", Options{SortCol: 1}, "a\t100\nb\t10\nc\t9\nname\tsize\n"},
+		{"lexical with header", Options{SortCol: 1, Header: true}, "name\tsize\na\t100\nb\t10\nc\t9\n"},
+		{"lexical numbers", Options{SortCol: 2, Header: true}, "name\tsize\nb\t10\na\t100\nc\t9\n"},
+		{"numeric", Options{SortCol: 2, Header: true, SortNumeric: true}, "name\tsize\nc\t9\nb\t10\na\t100\n"},
+		{"numeric reversed", Options{SortCol: 2, Header: true, SortNumeric: true, SortReverse: true}, "name\tsize\na\t100\nb\t10\nc\t9\n"},
+		{"header and footer", Options{SortCol: 1, Header: true, Footer: true}, "name\tsize\nb\t10\nc\t9\na\t100\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderTSV(t, in, tt.opts); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	tests := []struct {
+		in       string
+		width    int
+		ellipsis string
+		want     string
+	}{
+		{long, 10, "…", strings.Repeat("x", 9) + "…"},
+		{"short", 10, "…", "short"},
+		{"exactly10!", 10, "…", "exactly10!"},
+		{"漢字漢字漢字", 5, "…", "漢字…"},
+		{"漢字漢字漢字", 6, "…", "漢字…"},
+		{"\x1b[31m" + long + "\x1b[0m", 10, "…", "\x1b[31m" + strings.Repeat("x", 9) + "…\x1b[0m"},
+		{long, 1, "…", "…"},
+		{long, 10, "...", strings.Repeat("x", 7) + "..."},
+		{"exactly10!", 10, "...", "exactly10!"},
+		{"漢字漢字漢字", 6, "...", "漢..."},
+		{"漢字漢字漢字", 7, "...", "漢字..."},
+		{"\x1b[31m" + long + "\x1b[0m", 10, "...", "\x1b[31m" + strings.Repeat("x", 7) + "...\x1b[0m"},
+		{long, 3, "...", "..."},
+		{long, 5, "", "xxxxx"},
+	}
+	for _, tt := range tests {
+		got := truncate(tt.in, tt.width, tt.ellipsis)
+		if got != tt.want {
+			t.Errorf("truncate(%q, %d, %q) = %q, want %q", tt.in, tt.width, tt.ellipsis, got, tt.want)
+		}
+		if w := displayWidth(got); w > tt.width {
+			t.Errorf("truncate(%q, %d, %q) is %d wide", tt.in, tt.width, tt.ellipsis, w)
+		}
+	}
+}
+
+func TestRenderEllipsis(t *testing.T) {
+	in := strings.Repeat("x", 20) + "\tb\n"
+	tests := []struct {
+		ellipsis string
+		want     string
+	}{
+		{"", "xxxxxxxxx…\tb\n"},
+		{"…", "xxxxxxxxx…\tb\n"},
+		{"...", "xxxxxxx...\tb\n"},
+	}
+	for _, tt := range tests {
+		if got := renderTSV(t, in, Options{MaxCol: 10, Ellipsis: tt.ellipsis}); got != tt.want {
+			t.Errorf("Ellipsis %q: got %q, want %q", tt.ellipsis, got, tt.want)
+		}
+	}
+}
+
+func TestRenderMaxCol(t *testing.T) {
+	got, err := RenderString(strings.Repeat("x", 100)+"\tb\n", Options{Box: true, MaxCol: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "┌────────────┬───┐\n│ xxxxxxxxx… │ b │\n└────────────┴───┘\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	cell := strings.Split(got, "\n")[1]
+	cell = strings.TrimSpace(strings.Split(cell, "│")[1])
+	if w := displayWidth(cell); w != 10 {
+		t.Errorf("truncated cell %q is %d wide, want 10", cell, w)
+	}
+}
+
+func TestGroupDigits(t *testing.T) {
+	tests := []struct {
+		in   string
+		sep  rune
+		want string
+	}{
+		{"0", ',', "0"},
+		{"123", ',', "123"},
+		{"1234", ',', "1,234"},
+		{"1234567", ',', "1,234,567"},
+		{"1234567.891", ',', "1,234,567.891"},
+		{"1000.5", ',', "1,000.5"},
+		{"0.12345", ',', "0.12345"},
+		{"-1234", ',', "-1,234"},
+		{"-123456.78", ',', "-123,456.78"},
+		{"+1234", ',', "+1,234"},
+		{"1234.", ',', "1,234."},
+		{"1234567", '_', "1_234_567"},
+		{"1234567", '\u202f', "1\u202f234\u202f567"},
+		{"12ab", ',', "12ab"},
+		{"1e10", ',', "1e10"},
+		{"", ',', ""},
+	}
+	for _, tt := range tests {
+		if got := groupDigits(tt.in, tt.sep); got != tt.want {
+			t.Errorf("groupDigits(%q, %q) = %q, want %q", tt.in, tt.sep, got, tt.want)
+		}
+	}
+}
+
+func TestRenderGrouping(t *testing.T) {
+	const in = "name\tcount\tcode\na\t1234567\tx1\nb\t-9876.5\t22\n"
+	got := renderTSV(t, in, Options{Header: true, Grouping: ','})
+	want := "name\tcount\tcode\na\t1,234,567\tx1\nb\t-9,876.5\t22\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTrimCell(t *testing.T) {
+	tests := []struct {
+		in          string
+		left, right bool
+		want        string
+	}{
+		{"  a b  ", true, true, "a b"},
+		{"  a b  ", true, false, "a b  "},
+		{"  a b  ", false, true, "  a b"},
+		{"  a b  ", false, false, "  a b  "},
+		{" \t ", true, true, ""},
+		{" one \v  two ", true, true, "one\vtwo"},
+		{"\u00a0x\u3000", true, true, "x"},
+	}
+	for _, tt := range tests {
+		if got := trimCell(tt.in, tt.left, tt.right); got != tt.want {
+			t.Errorf("trimCell(%q, %v, %v) = %q, want %q", tt.in, tt.left, tt.right, got, tt.want)
+		}
+	}
+}
+
+func TestRenderTrim(t *testing.T) {
+	got, err := RenderString("  a  \tb\n   \t  cc\n", Options{Box: true, TrimLeft: true, TrimRight: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "┌───┬────┐\n│ a │ b  │\n│   │ cc │\n└───┴────┘\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderEmptyPlaceholder(t *testing.T) {
+	const in = "n\tv\tnote\na\t\t\nb\t2.5\tok\nc\t10\t\n\t\t\n"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "data cells",
+			opts: Options{Header: true, Footer: true, Empty: "-"},
+			want: "n\tv\tnote\na\t-\t-\nb\t2.5\tok\nc\t10\t-\n\t\t\n",
+		},
+		{
+			name: "without header or footer",
+			opts: Options{Empty: "N/A"},
+			want: "n\tv\tnote\na\tN/A\tN/A\nb\t2.5\tok\nc\t10\tN/A\nN/A\tN/A\tN/A\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderTSV(t, in, tt.opts); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	// A placeholder does not keep a column from being numeric.
+	got, err := RenderString(in, Options{Box: true, Header: true, Footer: true, AutoNum: true, Empty: "-", Cols: []int{0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "┏━━━┳━━━━━┓\n┃ n ┃   v ┃\n┡━━━╇━━━━━┩\n│ a │   - │\n│ b │ 2.5 │\n│ c │  10 │\n┢━━━╈━━━━━┪\n┃   ┃     ┃\n┗━━━┻━━━━━┛\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderRaggedRows(t *testing.T) {
+	const in = "a\tb\tc\nd\ne\tf\n"
+	tests := []struct {
+		name    string
+		opts    Options
+		want    string
+		wantErr bool
+	}{
+		{name: "kept ragged", opts: Options{Format: "tsv"}, want: "a\tb\tc\nd\ne\tf\n"},
+		{name: "padded", opts: Options{Format: "tsv", PadRows: true}, want: "a\tb\tc\nd\t\t\ne\tf\t\n"},
+		{name: "strict", opts: Options{Format: "tsv", Strict: true}, wantErr: true},
+		{name: "strict box", opts: Options{Box: true, Strict: true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderString(in, tt.opts)
+			switch {
+			case tt.wantErr && err == nil:
+				t.Errorf("got:\n%s\nwant an error", got)
+			case tt.wantErr && !strings.Contains(err.Error(), "row 2 has 1 fields, but row 1 has 3"):
+				t.Errorf("error = %q, want it to name row 2", err)
+			case !tt.wantErr && err != nil:
+				t.Error(err)
+			case got != tt.want:
+				t.Errorf("got:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+
+	if got, err := RenderString("a\tb\nc\td\n", Options{Format: "tsv", Strict: true}); err != nil || got != "a\tb\nc\td\n" {
+		t.Errorf("strict rectangular input = %q, %v", got, err)
+	}
+}
+
+func TestLessNatural(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"item2", "item10", true},
+		{"item10", "item2", false},
+		{"v1.9.0", "v1.10.0", true},
+		{"file007", "file8", true},
+		{"7", "007", true},
+		{"007", "7", false},
+		{"a", "a1", true},
+		{"x9y", "x10a", true},
+		{"abc", "abd", true},
+		{"10", "9", false},
+		{"same", "same", false},
+		{"", "a", true},
+	}
+	for _, tt := range tests {
+		if got := lessNatural(tt.a, tt.b); got != tt.want {
+			t.Errorf("lessNatural(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSortNatural(t *testing.T) {
+	const in = "file10\nfile2\nfile02\nfile1b\nfile1a\nFile3\nfile007\n"
+	got := renderTSV(t, in, Options{SortCol: 1, SortNatural: true})
+	want := "File3\nfile1a\nfile1b\nfile2\nfile02\nfile007\nfile10\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHeadTail(t *testing.T) {
+	const in = "n\n1\n2\n3\n4\n5\nsum\n"
+	tests := []struct {
+		name       string
+		head, tail int
+		want       string
+	}{
+		{"head", 2, 0, "n\n1\n2\nsum\n"},
+		{"tail", 0, 2, "n\n4\n5\nsum\n"},
+		{"head and tail", 4, 2, "n\n3\n4\nsum\n"},
+		{"head past the end", 10, 0, "n\n1\n2\n3\n4\n5\nsum\n"},
+		{"tail past the end", 0, 10, "n\n1\n2\n3\n4\n5\nsum\n"},
+		{"exactly all rows", 5, 5, "n\n1\n2\n3\n4\n5\nsum\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderTSV(t, in, Options{Header: true, Footer: true, Head: tt.head, Tail: tt.tail})
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if got, want := renderTSV(t, "3\n1\n2\n", Options{SortCol: 1, Head: 1}), "1\n"; got != want {
+		t.Errorf("head after sorting = %q, want %q", got, want)
+	}
+}
+
+func TestUniqueRows(t *testing.T) {
+	rows := [][]string{{"a", "1"}, {"b", "2"}, {"a", "1"}, {"a", "3"}, {"a1"}, {"b", "2", ""}}
+	tests := []struct {
+		name string
+		col  int
+		want [][]string
+	}{
+		{"full row", -1, [][]string{{"a", "1"}, {"b", "2"}, {"a", "3"}, {"a1"}, {"b", "2", ""}}},
+		{"first column", 0, [][]string{{"a", "1"}, {"b", "2"}, {"a1"}}},
+		{"second column", 1, [][]string{{"a", "1"}, {"b", "2"}, {"a", "3"}, {"a1"}}},
+		{"past the last column", 5, [][]string{{"a", "1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uniqueRows(rows, tt.col); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("uniqueRows(%d) = %q, want %q", tt.col, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderUnique(t *testing.T) {
+	const in = "name\tcolor\napple\tred\npear\tgreen\napple\tred\napple\tgreen\nname\tcolor\n"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"rows", Options{Header: true, Unique: true}, "name\tcolor\napple\tred\npear\tgreen\napple\tgreen\nname\tcolor\n"},
+		{"by key", Options{Header: true, UniqueBy: 2}, "name\tcolor\napple\tred\npear\tgreen\nname\tcolor\n"},
+		{"by key without header", Options{UniqueBy: 1}, "name\tcolor\napple\tred\npear\tgreen\n"},
+		{"sorted", Options{Header: true, UniqueBy: 1, SortCol: 2}, "name\tcolor\nname\tcolor\npear\tgreen\napple\tred\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderTSV(t, in, tt.opts); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderExclude(t *testing.T) {
+	const in = "a\tb\tc\td\n1\t2\t3\t4\n5\t6\n"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"interior", Options{Exclude: []int{1, 2}}, "a\td\n1\t4\n5\n"},
+		{"trailing", Options{Exclude: []int{3}}, "a\tb\tc\n1\t2\t3\n5\t6\n"},
+		{"first", Options{Exclude: []int{0}}, "b\tc\td\n2\t3\t4\n6\n"},
+		{"out of range", Options{Exclude: []int{9}}, "a\tb\tc\td\n1\t2\t3\t4\n5\t6\n"},
+		{"with cols", Options{Cols: []int{3, 1, 0}, Exclude: []int{1}}, "d\ta\n4\t1\n\t5\n"},
+		{"every column", Options{Exclude: []int{0, 1, 2, 3}}, "\n\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderTSV(t, in, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputColumns(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		col  int
+		want []int
+	}{
+		{"unchanged", Options{}, 2, []int{2}},
+		{"after an excluded column", Options{Exclude: []int{0}}, 2, []int{1}},
+		{"excluded", Options{Exclude: []int{2}}, 2, nil},
+		{"selected", Options{Cols: []int{2, 0, 2}}, 2, []int{0, 2}},
+		{"selected and excluded", Options{Cols: []int{1, 0, 2}, Exclude: []int{1}}, 2, []int{1}},
+		{"numbered", Options{Number: true, Exclude: []int{0}}, 1, []int{1}},
+		{"excluded twice", Options{Exclude: []int{0, 0, 1}}, 2, []int{0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.outputColumns(tt.col); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("outputColumns(%d) = %v, want %v", tt.col, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSortKeys(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []SortKey
+		wantErr bool
+	}{
+		{"2", []SortKey{{Col: 1}}, false},
+		{"2d,1", []SortKey{{Col: 1, Reverse: true}, {Col: 0}}, false},
+		{"3nd,1a", []SortKey{{Col: 2, Compare: "numeric", Reverse: true}, {Col: 0}}, false},
+		{"1v,2l", []SortKey{{Col: 0, Compare: "natural"}, {Col: 1, Compare: "lexical"}}, false},
+		{"1da", []SortKey{{Col: 0}}, false},
+		{"", nil, true},
+		{"0", nil, true},
+		{"d", nil, true},
+		{"2x", nil, true},
+		{"2,,1", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSortKeys(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSortKeys(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSortKeys(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSortKeys(t *testing.T) {
+	const in = "category\tname\tqty\nfruit\tpear\t10\nveg\tleek\t2\nfruit\tapple\t9\nveg\tkale\t2\nfruit\tfig\t10\n"
+	tests := []struct {
+		name string
+		keys string
+		opts Options
+		want string
+	}{
+		{"two keys", "1,2", Options{}, "fruit\tapple\t9\nfruit\tfig\t10\nfruit\tpear\t10\nveg\tkale\t2\nveg\tleek\t2\n"},
+		{"second descending", "1,2d", Options{}, "fruit\tpear\t10\nfruit\tfig\t10\nfruit\tapple\t9\nveg\tleek\t2\nveg\tkale\t2\n"},
+		{"first descending", "1d,2", Options{}, "veg\tkale\t2\nveg\tleek\t2\nfruit\tapple\t9\nfruit\tfig\t10\nfruit\tpear\t10\n"},
+		{"numeric", "3n,2", Options{}, "veg\tkale\t2\nveg\tleek\t2\nfruit\tapple\t9\nfruit\tfig\t10\nfruit\tpear\t10\n"},
+		{"lexical numbers", "3l,2", Options{}, "fruit\tfig\t10\nfruit\tpear\t10\nveg\tkale\t2\nveg\tleek\t2\nfruit\tapple\t9\n"},
+		{"stable", "1", Options{}, "fruit\tpear\t10\nfruit\tapple\t9\nfruit\tfig\t10\nveg\tleek\t2\nveg\tkale\t2\n"},
+		{"reversed", "1,2", Options{SortReverse: true}, "veg\tleek\t2\nveg\tkale\t2\nfruit\tpear\t10\nfruit\tfig\t10\nfruit\tapple\t9\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseSortKeys(tt.keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.opts.SortKeys, tt.opts.Header = keys, true
+			got := renderTSV(t, in, tt.opts)
+			if want := "category\tname\tqty\n" + tt.want; got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestSortIgnoreCase(t *testing.T) {
+	const in = "banana\nApple\ncherry\napple\nBanana\n"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"case", Options{SortCol: 1}, "Apple\nBanana\napple\nbanana\ncherry\n"},
+		{"ignoring case", Options{SortCol: 1, IgnoreCase: true}, "Apple\napple\nbanana\nBanana\ncherry\n"},
+		{"reversed", Options{SortCol: 1, IgnoreCase: true, SortReverse: true}, "cherry\nbanana\nBanana\nApple\napple\n"},
+		{"keys", Options{SortKeys: []SortKey{{Col: 0, Reverse: true}}, IgnoreCase: true}, "cherry\nbanana\nBanana\nApple\napple\n"},
+		{"natural", Options{SortCol: 1, SortNatural: true, IgnoreCase: true}, "Apple\napple\nbanana\nBanana\ncherry\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderTSV(t, in, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderIgnoreCase(t *testing.T) {
+	f, err := ParseFilter("1~^a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := renderTSV(t, "name\nbanana\nAvocado\napple\nApricot\n", Options{Header: true, SortCol: 1, IgnoreCase: true, Filters: []Filter{f}})
+	if want := "name\napple\nApricot\nAvocado\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCollapseRows(t *testing.T) {
+	tests := []struct {
+		name string
+		col  []string
+		want []string
+	}{
+		{"consecutive", []string{"a", "a", "a", "b", "b"}, []string{"a", "", "", "b", ""}},
+		{"not consecutive", []string{"a", "b", "a", "b"}, []string{"a", "b", "a", "b"}},
+		{"returning", []string{"a", "a", "b", "a", "a"}, []string{"a", "", "b", "a", ""}},
+		{"empty cells", []string{"", "", "a"}, []string{"", "", "a"}},
+		{"case", []string{"a", "A"}, []string{"a", "A"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := make([][]string, len(tt.col))
+			for n, c := range tt.col {
+				rows[n] = []string{c, strconv.Itoa(n)}
+			}
+			collapseRows(rows, 0)
+			for n, row := range rows {
+				if row[0] != tt.want[n] || row[1] != strconv.Itoa(n) {
+					t.Errorf("row %d = %q, want %q", n, row, []string{tt.want[n], strconv.Itoa(n)})
+				}
+			}
+		})
+	}
+
+	// A row too short for the column breaks a run.
+	rows := [][]string{{"x", "a"}, {"x"}, {"x", "a"}}
+	collapseRows(rows, 1)
+	if rows[2][1] != "a" {
+		t.Errorf("cell after a short row collapsed: %q", rows)
+	}
+}
+
+func TestRenderCollapse(t *testing.T) {
+	const in = "team\tname\nred\tann\nblue\tbob\nred\tcat\nblue\tdan\nred\teve\n"
+	got, err := RenderString(in, Options{Box: true, Header: true, Collapse: []int{0}, SortCol: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "┏━━━━━━┳━━━━━━┓\n" +
+		"┃ team ┃ name ┃\n" +
+		"┡━━━━━━╇━━━━━━┩\n" +
+		"│ blue │ bob  │\n" +
+		"│      │ dan  │\n" +
+		"│ red  │ ann  │\n" +
+		"│      │ cat  │\n" +
+		"│      │ eve  │\n" +
+		"└──────┴──────┘\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Unsorted, only consecutive repeats are blanked, and the header is never compared.
+	got = renderTSV(t, "team\nteam\nred\nred\nblue\nred\n", Options{Header: true, Collapse: []int{0}})
+	if want := "team\nteam\nred\n\nblue\nred\n"; got != want {
+		t.Errorf("unsorted: got %q, want %q", got, want)
+	}
+}
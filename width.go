@@ -0,0 +1,169 @@
+package ftable
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// wideRanges are the ranges of code points that occupy two terminal cells: those with an East Asian Width of
+// Wide or Fullwidth.
+var wideRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x1100, 0x115f, 1}, // Hangul Jamo initial consonants
+		{0x231a, 0x231b, 1},
+		{0x2329, 0x232a, 1},
+		{0x23e9, 0x23ec, 1}, // Emoji with default emoji presentation, from here through 0x2b55
+		{0x23f0, 0x23f3, 3},
+		{0x25fd, 0x25fe, 1},
+		{0x2614, 0x2615, 1},
+		{0x2648, 0x2653, 1},
+		{0x267f, 0x2693, 20},
+		{0x26a1, 0x26aa, 9},
+		{0x26ab, 0x26bd, 18},
+		{0x26be, 0x26c4, 6},
+		{0x26c5, 0x26ce, 9},
+		{0x26d4, 0x26ea, 22},
+		{0x26f2, 0x26f3, 1},
+		{0x26f5, 0x26fa, 5},
+		{0x26fd, 0x2705, 8},
+		{0x270a, 0x270b, 1},
+		{0x2728, 0x274c, 36},
+		{0x274e, 0x2753, 5},
+		{0x2754, 0x2755, 1},
+		{0x2757, 0x2795, 62},
+		{0x2796, 0x2797, 1},
+		{0x27b0, 0x27bf, 15},
+		{0x2b1b, 0x2b1c, 1},
+		{0x2b50, 0x2b55, 5},
+		{0x2e80, 0x303e, 1}, // CJK radicals, Kangxi radicals, CJK symbols and punctuation
+		{0x3041, 0x33ff, 1}, // Hiragana, Katakana, Bopomofo, Hangul compatibility Jamo, Kanbun, CJK compatibility
+		{0x3400, 0x4dbf, 1}, // CJK unified ideographs extension A
+		{0x4e00, 0x9fff, 1}, // CJK unified ideographs
+		{0xa000, 0xa4cf, 1}, // Yi syllables and radicals
+		{0xa960, 0xa97f, 1}, // Hangul Jamo extended A
+		{0xac00, 0xd7a3, 1}, // Hangul syllables
+		{0xf900, 0xfaff, 1}, // CJK compatibility ideographs
+		{0xfe10, 0xfe19, 1}, // Vertical forms
+		{0xfe30, 0xfe6f, 1}, // CJK compatibility forms, small form variants
+		{0xff00, 0xff60, 1}, // Fullwidth forms
+		{0xffe0, 0xffe6, 1}, // Fullwidth signs
+	},
+	R32: []unicode.Range32{
+		{0x16fe0, 0x16fe4, 1},
+		{0x17000, 0x18aff, 1}, // Tangut
+		{0x1b000, 0x1b2ff, 1}, // Kana supplement and extended
+		{0x1f004, 0x1f0cf, 203},
+		{0x1f18e, 0x1f191, 3},
+		{0x1f192, 0x1f19a, 1},
+		{0x1f200, 0x1f202, 1}, // Enclosed ideographic supplement
+		{0x1f210, 0x1f23b, 1},
+		{0x1f240, 0x1f248, 1},
+		{0x1f250, 0x1f251, 1},
+		{0x1f300, 0x1f64f, 1}, // Miscellaneous symbols and pictographs, emoticons
+		{0x1f680, 0x1f6ff, 1}, // Transport and map symbols
+		{0x1f7e0, 0x1f7eb, 1},
+		{0x1f90c, 0x1f9ff, 1}, // Supplemental symbols and pictographs
+		{0x1fa70, 0x1faff, 1}, // Symbols and pictographs extended A
+		{0x20000, 0x2fffd, 1}, // CJK unified ideographs extensions B through F
+		{0x30000, 0x3fffd, 1}, // CJK unified ideographs extension G
+	},
+}
+
+// runeWidth returns the number of terminal cells occupied by r on its own: 0 for control characters and
+// combining marks, 2 for wide and fullwidth characters, and 1 otherwise.
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case r < 0x300:
+		return 1
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case unicode.Is(wideRanges, r):
+		return 2
+	}
+	return 1
+}
+
+const (
+	zeroWidthJoiner = '\u200d'
+	emojiVariation  = '\ufe0f'
+)
+
+// isRegionalIndicator reports whether r is one of the regional indicator symbols, pairs of which form flags.
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// extendsCluster reports whether r continues the grapheme cluster preceding it, rather than starting its own.
+func extendsCluster(r rune) bool {
+	switch {
+	case r == zeroWidthJoiner,
+		r >= 0xfe00 && r <= 0xfe0f,   // Variation selectors
+		r >= 0x1f3fb && r <= 0x1f3ff, // Emoji skin tone modifiers
+		r >= 0xe0020 && r <= 0xe007f: // Tags, as in subdivision flags
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me)
+}
+
+// nextCluster returns the length, in bytes, and display width of the grapheme cluster at the start of s. This is
+// an approximation of Unicode's extended grapheme clusters that covers combining marks, emoji modifier and ZWJ
+// sequences, variation selectors, and flags (pairs of regional indicators), which is enough to measure most
+// text as a terminal draws it: a cluster is as wide as its first character, or two cells wide if it is an
+// emoji presentation sequence or a flag.
+func nextCluster(s string) (n, width int) {
+	r, n := utf8.DecodeRuneInString(s)
+	width = runeWidth(r)
+	if isRegionalIndicator(r) {
+		if next, size := utf8.DecodeRuneInString(s[n:]); isRegionalIndicator(next) {
+			return n + size, 2
+		}
+		return n, 1
+	}
+
+	for joined := false; n < len(s); {
+		next, size := utf8.DecodeRuneInString(s[n:])
+		if !joined && !extendsCluster(next) {
+			break
+		}
+		if next == emojiVariation && width == 1 {
+			width = 2
+		}
+		joined = next == zeroWidthJoiner
+		n += size
+	}
+	return n, width
+}
+
+// displayWidth returns the number of terminal cells occupied by s. ANSI escape sequences occupy none.
+func displayWidth(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		if skip := escapeLen(s[i:]); skip > 0 {
+			i += skip
+			continue
+		}
+		size, width := nextCluster(s[i:])
+		n += width
+		i += size
+	}
+	return n
+}
+
+// measure returns the display width of s and of its widest grapheme cluster, decoding s only once.
+func measure(s string) (width, widest int) {
+	for i := 0; i < len(s); {
+		if skip := escapeLen(s[i:]); skip > 0 {
+			i += skip
+			continue
+		}
+		size, w := nextCluster(s[i:])
+		width += w
+		if w > widest {
+			widest = w
+		}
+		i += size
+	}
+	return width, widest
+}
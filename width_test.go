@@ -0,0 +1,77 @@
+package ftable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"漢字", 4},
+		{"a漢b", 4},
+		{"日本語テキスト", 14},
+		{"한국어", 6},
+		{"ＡＢＣ", 6},
+		{"ｱｲｳ", 3},
+		{"\x1b[31mred\x1b[0m", 3},
+		{"\x1b[1;38;5;208mbold\x1b[m orange", 11},
+		{"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", 4},
+		{"\x1b[32m漢字\x1b[0m", 4},
+		{"👍", 2},
+		{"👍🏽", 2},
+		{"👩‍💻", 2},
+		{"👨‍👩‍👧‍👦", 2},
+		{"🇯🇵", 2},
+		{"❤️", 2},
+		{"e\u0301", 1},
+		{"ok ✅", 5},
+	}
+	for _, tt := range tests {
+		if got := displayWidth(tt.in); got != tt.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+// BenchmarkMeasure measures the width and widest cluster of a line, as newBox does for every cell: "twice" with
+// separate passes over the line for each, as before measure, and "once" with measure.
+func BenchmarkMeasure(b *testing.B) {
+	line := strings.Repeat("naïve 漢字 \x1b[1mbold\x1b[0m 👍🏽 ", 8)
+	widest := func(s string) int {
+		n := 0
+		for i := 0; i < len(s); {
+			if skip := escapeLen(s[i:]); skip > 0 {
+				i += skip
+				continue
+			}
+			size, w := nextCluster(s[i:])
+			if w > n {
+				n = w
+			}
+			i += size
+		}
+		return n
+	}
+
+	b.Run("twice", func(b *testing.B) {
+		b.SetBytes(int64(len(line)))
+		for i := 0; i < b.N; i++ {
+			if displayWidth(line) == 0 || widest(line) == 0 {
+				b.Fatal("empty measure")
+			}
+		}
+	})
+	b.Run("once", func(b *testing.B) {
+		b.SetBytes(int64(len(line)))
+		for i := 0; i < b.N; i++ {
+			if width, w := measure(line); width == 0 || w == 0 {
+				b.Fatal("empty measure")
+			}
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package ftable
+
+import "strings"
+
+// wrapText breaks s into lines no wider than width. Lines are broken at spaces where possible; a word wider than
+// width is broken wherever it reaches width, between grapheme clusters. The spaces at which a line is broken are
+// dropped. Colors and hyperlinks spanning lines are reflowed by reflowEscapes. If width is less than 1, s is
+// returned as a single line.
+func wrapText(s string, width int) []string {
+	if width < 1 || displayWidth(s) <= width {
+		return []string{s}
+	}
+
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+	flush := func() {
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+		line.Reset()
+		lineWidth = 0
+	}
+
+	for _, word := range splitWords(s) {
+		ww := displayWidth(strings.TrimRight(word, " "))
+		if lineWidth > 0 && lineWidth+ww > width {
+			flush()
+		}
+
+		// Hard-break any word that cannot fit on a line of its own.
+		for ww > width {
+			head, rest := splitWidth(word, width-lineWidth)
+			line.WriteString(head)
+			flush()
+			word, ww = rest, displayWidth(strings.TrimRight(rest, " "))
+		}
+
+		line.WriteString(word)
+		lineWidth += displayWidth(word)
+	}
+	if lineWidth > 0 || len(lines) == 0 {
+		flush()
+	}
+	if strings.IndexByte(s, '\x1b') >= 0 {
+		reflowEscapes(lines)
+	}
+	return lines
+}
+
+// splitWords splits s after each space, as strings.SplitAfter does, save for spaces within escape sequences (such
+// as the URL of a hyperlink), which are never split.
+func splitWords(s string) []string {
+	var words []string
+	start := 0
+	for i := 0; i < len(s); {
+		if skip := escapeLen(s[i:]); skip > 0 {
+			i += skip
+			continue
+		}
+		i++
+		if s[i-1] == ' ' {
+			words = append(words, s[start:i])
+			start = i
+		}
+	}
+	return append(words, s[start:])
+}
+
+// splitWidth splits s after as many of its leading grapheme clusters as fit in width, keeping any escape
+// sequences that immediately follow them. At least one cluster is always kept in head, so that progress is made
+// even if width is too narrow for it.
+func splitWidth(s string, width int) (head, rest string) {
+	n, used := 0, 0
+	for n < len(s) {
+		if skip := escapeLen(s[n:]); skip > 0 {
+			n += skip
+			continue
+		}
+		size, w := nextCluster(s[n:])
+		if used+w > width && used > 0 {
+			break
+		}
+		n += size
+		used += w
+	}
+	return s[:n], s[n:]
+}
@@ -0,0 +1,128 @@
+package ftable
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	red   = "\x1b[31m"
+	bold  = "\x1b[1m"
+	reset = "\x1b[0m"
+)
+
+// checkEscapes fails t unless every escape character of line begins a whole escape sequence.
+func checkEscapes(t *testing.T, line string) {
+	t.Helper()
+	for i := 0; i < len(line); i++ {
+		if line[i] != '\x1b' {
+			continue
+		}
+		n := escapeLen(line[i:])
+		if n == 0 {
+			t.Errorf("line %q has a broken escape sequence at offset %d", line, i)
+			return
+		}
+		i += n - 1
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width int
+		want  []string
+	}{
+		{"fits", "one two", 7, []string{"one two"}},
+		{"words", "one two three", 7, []string{"one two", "three"}},
+		{"long word", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"wide", "漢字漢字漢", 4, []string{"漢字", "漢字", "漢"}},
+		{"no width", "one two three", 0, []string{"one two three"}},
+		{
+			"color across lines", red + "one two three" + reset, 7,
+			[]string{red + "one two" + reset, red + "three" + reset},
+		},
+		{
+			"color within a line", "one " + red + "two" + reset + " three", 7,
+			[]string{"one " + red + "two" + reset, "three"},
+		},
+		{
+			"stacked colors", bold + red + "aaaa bbbb" + reset, 4,
+			[]string{bold + red + "aaaa" + reset, bold + red + "bbbb" + reset},
+		},
+		{
+			"broken word", red + "abcdefgh" + reset, 3,
+			[]string{red + "abc" + reset, red + "def" + reset, red + "gh" + reset},
+		},
+		{
+			"hyperlink", "\x1b]8;;http://x/a b\x1b\\link text\x1b]8;;\x1b\\", 4,
+			[]string{
+				"\x1b]8;;http://x/a b\x1b\\link\x1b]8;;\x1b\\",
+				"\x1b]8;;http://x/a b\x1b\\text\x1b]8;;\x1b\\",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapText(tt.in, tt.width)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapText(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+			}
+			for _, line := range got {
+				checkEscapes(t, line)
+				if w := displayWidth(line); tt.width > 0 && w > tt.width {
+					t.Errorf("line %q is %d wide, want at most %d", line, w, tt.width)
+				}
+			}
+			if text, want := stripANSI(strings.Join(got, "")), strings.Replace(stripANSI(tt.in), " ", "", -1); strings.Replace(text, " ", "", -1) != want {
+				t.Errorf("wrapped text %q, want %q", text, want)
+			}
+		})
+	}
+}
+
+func TestWrapTextEscapesWhole(t *testing.T) {
+	// Wrap colored words at every width, so that some break lands at each position of the escapes.
+	in := red + "alpha" + reset + " " + "\x1b[38;5;208m" + "beta gamma" + reset + bold + "delta" + reset
+	for width := 1; width <= displayWidth(in); width++ {
+		for _, line := range wrapText(in, width) {
+			checkEscapes(t, line)
+			if w := displayWidth(line); w > width {
+				t.Errorf("width %d: line %q is %d wide", width, line, w)
+			}
+		}
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"a b  c", []string{"a ", "b ", " ", "c"}},
+		{"a ", []string{"a ", ""}},
+		{"\x1b]8;;http://x/a b\x1b\\x y", []string{"\x1b]8;;http://x/a b\x1b\\x ", "y"}},
+	}
+	for _, tt := range tests {
+		if got := splitWords(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitWords(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBoxWrapColored(t *testing.T) {
+	in := "name\t" + red + "a long red note that must wrap" + reset + "\n"
+	got, err := RenderString(in, Options{Box: true, Width: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAligned(t, got)
+	for _, line := range strings.Split(got, "\n") {
+		checkEscapes(t, line)
+		if strings.Contains(line, red) && !strings.Contains(line, reset) {
+			t.Errorf("line %q is not reset before its border", line)
+		}
+	}
+}